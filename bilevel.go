@@ -0,0 +1,245 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+)
+
+// BilevelPNG returns the QR Code as a 1-bit grayscale PNG of size x size
+// pixels, with dark modules in black whatever the colours. Rows are stored
+// unfiltered, which suits bilevel images and keeps encoding fast.
+func (q *QRCode) BilevelPNG(size int) ([]byte, error) {
+	img, err := q.bilevel(size)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString("\x89PNG\r\n\x1a\n")
+
+	n := img.Bounds().Dx()
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(n))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(n))
+	ihdr[8] = 1 // Bit depth.
+	ihdr[9] = 0 // Grayscale.
+
+	pngChunk(&b, "IHDR", ihdr)
+
+	if q.DPI > 0 {
+		pngChunk(&b, "pHYs", pngPhys(q.DPI))
+	}
+
+	var data bytes.Buffer
+
+	zw := zlib.NewWriter(&data)
+
+	// Each row starts with filter type 0. Grayscale 0 is black, so the bits
+	// of white pixels are set.
+	row := make([]byte, 1+(n+7)/8)
+
+	for y := 0; y < n; y++ {
+		q.packBits(row[1:], img, y, false, true)
+
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	pngChunk(&b, "IDAT", data.Bytes())
+	pngChunk(&b, "IEND", nil)
+
+	return q.base64(b.Bytes(), "image/png"), nil
+}
+
+// PBM returns the QR Code as a binary Netpbm bitmap of size x size pixels.
+func (q *QRCode) PBM(size int) ([]byte, error) {
+	img, err := q.bilevel(size)
+	if err != nil {
+		return nil, err
+	}
+
+	n := img.Bounds().Dx()
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "P4\n%d %d\n", n, n)
+
+	row := make([]byte, (n+7)/8)
+
+	for y := 0; y < n; y++ {
+		q.packBits(row, img, y, false, false)
+		b.Write(row)
+	}
+
+	return q.base64(b.Bytes(), "image/x-portable-bitmap"), nil
+}
+
+// PGM returns the QR Code as a binary Netpbm graymap of size x size pixels,
+// for software that doesn't read bitmaps.
+func (q *QRCode) PGM(size int) ([]byte, error) {
+	img, err := q.bilevel(size)
+	if err != nil {
+		return nil, err
+	}
+
+	n := img.Bounds().Dx()
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "P5\n%d %d\n255\n", n, n)
+
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if q.isBlack(img, x, y) {
+				b.WriteByte(0)
+			} else {
+				b.WriteByte(0xff)
+			}
+		}
+	}
+
+	return q.base64(b.Bytes(), "image/x-portable-graymap"), nil
+}
+
+// XBM returns the QR Code as an X bitmap of size x size pixels, a C source
+// fragment defining qrcode_width, qrcode_height and qrcode_bits.
+func (q *QRCode) XBM(size int) ([]byte, error) {
+	img, err := q.bilevel(size)
+	if err != nil {
+		return nil, err
+	}
+
+	n := img.Bounds().Dx()
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "#define qrcode_width %d\n#define qrcode_height %d\n", n, n)
+	b.WriteString("static unsigned char qrcode_bits[] = {")
+
+	row := make([]byte, (n+7)/8)
+	i := 0
+
+	for y := 0; y < n; y++ {
+		q.packBits(row, img, y, true, false)
+
+		for _, v := range row {
+			if i > 0 {
+				b.WriteString(",")
+			}
+
+			if i%12 == 0 {
+				b.WriteString("\n  ")
+			} else {
+				b.WriteString(" ")
+			}
+
+			fmt.Fprintf(&b, "0x%02x", v)
+			i++
+		}
+	}
+
+	b.WriteString("\n};\n")
+
+	return q.base64(b.Bytes(), "image/x-xbitmap"), nil
+}
+
+// bilevel returns the QR Code as a size x size image with colour index 1 for
+// dark modules, honouring shapes but not colours or anti-aliasing.
+func (q *QRCode) bilevel(size int) (*image.Paletted, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	return q.bilevelLayout(q.rasterSize(size)), nil
+}
+
+// bilevelLayout draws the encoded QR Code as a bilevel image laid out by l.
+func (q *QRCode) bilevelLayout(l rasterLayout) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, l.size, l.size), color.Palette{color.White, color.Black})
+
+	for _, ly := range q.layers() {
+		if ly.modules != nil {
+			if isSquare(ly.shape) {
+				fillModules(img, l, ly.modules, 1)
+			} else {
+				fillPaths(img, l, modulePaths(ly.modules, ly.shape), 1)
+			}
+		}
+
+		fillPaths(img, l, ly.paths, 1)
+	}
+
+	return img
+}
+
+// isBlack reports whether pixel (x, y) of a bilevel image is printed black,
+// which dark modules are unless Inverted.
+func (q *QRCode) isBlack(img *image.Paletted, x int, y int) bool {
+	return (img.Pix[y*img.Stride+x] == 1) != q.Inverted
+}
+
+// packBits packs row y of a bilevel image into dst, eight pixels per byte,
+// with the leftmost pixel in the least significant bit if lsb is set and in
+// the most significant bit otherwise. Bits of black pixels are set, or those
+// of white pixels if white is set. Padding bits are zero.
+func (q *QRCode) packBits(dst []byte, img *image.Paletted, y int, lsb bool, white bool) {
+	for i := range dst {
+		dst[i] = 0
+	}
+
+	n := img.Bounds().Dx()
+
+	for x := 0; x < n; x++ {
+		if q.isBlack(img, x, y) == white {
+			continue
+		}
+
+		if lsb {
+			dst[x/8] |= 1 << uint(x%8)
+		} else {
+			dst[x/8] |= 0x80 >> uint(x%8)
+		}
+	}
+}
+
+// pngChunk appends a PNG chunk of type typ to b.
+func pngChunk(b *bytes.Buffer, typ string, data []byte) {
+	var header [8]byte
+
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	copy(header[4:], typ)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	var sum [4]byte
+
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+
+	b.Write(header[:])
+	b.Write(data)
+	b.Write(sum[:])
+}
+
+// base64 returns b as a data URI of type mime if Base64 is set.
+func (q *QRCode) base64(b []byte, mime string) []byte {
+	if !q.Base64 {
+		return b
+	}
+
+	return []byte(fmt.Sprintf("data:%s;base64,%s", mime, base64.StdEncoding.EncodeToString(b)))
+}
@@ -0,0 +1,46 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestBilevelPNG(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.DPI = 300
+
+	b, err := q.BilevelPNG(-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := q.bilevel(-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img.Bounds() != want.Bounds() {
+		t.Fatalf("bounds %v, want %v", img.Bounds(), want.Bounds())
+	}
+
+	for y := 0; y < want.Rect.Dy(); y++ {
+		for x := 0; x < want.Rect.Dx(); x++ {
+			black := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y == 0
+
+			if black != q.isBlack(want, x, y) {
+				t.Fatalf("pixel (%d, %d) black = %v", x, y, black)
+			}
+		}
+	}
+}
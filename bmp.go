@@ -0,0 +1,104 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+)
+
+// BMP returns the QR Code as a Windows bitmap of size x size pixels. Images
+// in two colours, such as plain black and white codes, are stored with 1 bit
+// per pixel, other paletted images with 8 and the rest with 24. Transparency
+// is flattened onto Matte.
+func (q *QRCode) BMP(size int) ([]byte, error) {
+	img, err := q.image(size)
+	if err != nil {
+		return nil, err
+	}
+
+	var palette color.Palette
+
+	bpp := 24
+
+	if p, ok := img.(*image.Paletted); ok {
+		palette = p.Palette
+
+		bpp = 8
+		if len(palette) <= 2 {
+			bpp = 1
+		}
+	} else {
+		img = q.flatten(img)
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Rows are padded to a multiple of 4 bytes.
+	stride := (w*bpp + 31) / 32 * 4
+
+	const headerSize = 14 + 40
+
+	offset := headerSize + 4*len(palette)
+	fileSize := offset + stride*h
+
+	var ppm uint32
+	if q.DPI > 0 {
+		ppm = uint32(math.Round(q.DPI / 0.0254))
+	}
+
+	header := make([]byte, headerSize)
+
+	// File header.
+	copy(header, "BM")
+	binary.LittleEndian.PutUint32(header[2:], uint32(fileSize))
+	binary.LittleEndian.PutUint32(header[10:], uint32(offset))
+
+	// Info header, for an uncompressed bottom up image.
+	binary.LittleEndian.PutUint32(header[14:], 40)
+	binary.LittleEndian.PutUint32(header[18:], uint32(w))
+	binary.LittleEndian.PutUint32(header[22:], uint32(h))
+	binary.LittleEndian.PutUint16(header[26:], 1) // Planes.
+	binary.LittleEndian.PutUint16(header[28:], uint16(bpp))
+	binary.LittleEndian.PutUint32(header[34:], uint32(stride*h))
+	binary.LittleEndian.PutUint32(header[38:], ppm)
+	binary.LittleEndian.PutUint32(header[42:], ppm)
+	binary.LittleEndian.PutUint32(header[46:], uint32(len(palette)))
+
+	var out bytes.Buffer
+
+	out.Grow(fileSize)
+	out.Write(header)
+
+	for _, c := range palette {
+		n := color.RGBAModel.Convert(composite(c, q.matte())).(color.RGBA)
+		out.Write([]byte{n.B, n.G, n.R, 0})
+	}
+
+	row := make([]byte, stride)
+
+	// Rows are stored bottom up.
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		for i := range row {
+			row[i] = 0
+		}
+
+		for x := 0; x < w; x++ {
+			switch bpp {
+			case 1:
+				row[x/8] |= img.(*image.Paletted).ColorIndexAt(b.Min.X+x, y) << uint(7-x%8)
+			case 8:
+				row[x] = img.(*image.Paletted).ColorIndexAt(b.Min.X+x, y)
+			default:
+				n := color.RGBAModel.Convert(img.At(b.Min.X+x, y)).(color.RGBA)
+				row[3*x], row[3*x+1], row[3*x+2] = n.B, n.G, n.R
+			}
+		}
+
+		out.Write(row)
+	}
+
+	return q.base64(out.Bytes(), "image/bmp"), nil
+}
@@ -0,0 +1,188 @@
+package qrcode
+
+// g4Code is a variable length code of n bits, most significant first.
+type g4Code struct {
+	bits uint16
+	n    uint8
+}
+
+// Mode codes of T.6 two-dimensional coding.
+var (
+	g4Pass       = g4Code{0x1, 4}
+	g4Horizontal = g4Code{0x1, 3}
+
+	// Vertical mode codes for a1 - b1 from -3 to 3.
+	g4Vertical = [...]g4Code{{0x02, 7}, {0x02, 6}, {0x2, 3}, {0x1, 1}, {0x3, 3}, {0x03, 6}, {0x03, 7}}
+
+	// End of line, written twice to end the data.
+	g4EOL = g4Code{0x001, 12}
+)
+
+// g4WhiteCodes holds the white run codes: terminating codes for runs of 0
+// to 63, then make-up codes for multiples of 64 up to 2560.
+var g4WhiteCodes = [...]g4Code{
+	{0x035, 8}, {0x007, 6}, {0x007, 4}, {0x008, 4}, {0x00b, 4}, {0x00c, 4},
+	{0x00e, 4}, {0x00f, 4}, {0x013, 5}, {0x014, 5}, {0x007, 5}, {0x008, 5},
+	{0x008, 6}, {0x003, 6}, {0x034, 6}, {0x035, 6}, {0x02a, 6}, {0x02b, 6},
+	{0x027, 7}, {0x00c, 7}, {0x008, 7}, {0x017, 7}, {0x003, 7}, {0x004, 7},
+	{0x028, 7}, {0x02b, 7}, {0x013, 7}, {0x024, 7}, {0x018, 7}, {0x002, 8},
+	{0x003, 8}, {0x01a, 8}, {0x01b, 8}, {0x012, 8}, {0x013, 8}, {0x014, 8},
+	{0x015, 8}, {0x016, 8}, {0x017, 8}, {0x028, 8}, {0x029, 8}, {0x02a, 8},
+	{0x02b, 8}, {0x02c, 8}, {0x02d, 8}, {0x004, 8}, {0x005, 8}, {0x00a, 8},
+	{0x00b, 8}, {0x052, 8}, {0x053, 8}, {0x054, 8}, {0x055, 8}, {0x024, 8},
+	{0x025, 8}, {0x058, 8}, {0x059, 8}, {0x05a, 8}, {0x05b, 8}, {0x04a, 8},
+	{0x04b, 8}, {0x032, 8}, {0x033, 8}, {0x034, 8}, {0x01b, 5}, {0x012, 5},
+	{0x017, 6}, {0x037, 7}, {0x036, 8}, {0x037, 8}, {0x064, 8}, {0x065, 8},
+	{0x068, 8}, {0x067, 8}, {0x0cc, 9}, {0x0cd, 9}, {0x0d2, 9}, {0x0d3, 9},
+	{0x0d4, 9}, {0x0d5, 9}, {0x0d6, 9}, {0x0d7, 9}, {0x0d8, 9}, {0x0d9, 9},
+	{0x0da, 9}, {0x0db, 9}, {0x098, 9}, {0x099, 9}, {0x09a, 9}, {0x018, 6},
+	{0x09b, 9}, {0x008, 11}, {0x00c, 11}, {0x00d, 11}, {0x012, 12}, {0x013, 12},
+	{0x014, 12}, {0x015, 12}, {0x016, 12}, {0x017, 12}, {0x01c, 12}, {0x01d, 12},
+	{0x01e, 12}, {0x01f, 12},
+}
+
+// g4BlackCodes holds the black run codes: terminating codes for runs of 0
+// to 63, then make-up codes for multiples of 64 up to 2560.
+var g4BlackCodes = [...]g4Code{
+	{0x037, 10}, {0x002, 3}, {0x003, 2}, {0x002, 2}, {0x003, 3}, {0x003, 4},
+	{0x002, 4}, {0x003, 5}, {0x005, 6}, {0x004, 6}, {0x004, 7}, {0x005, 7},
+	{0x007, 7}, {0x004, 8}, {0x007, 8}, {0x018, 9}, {0x017, 10}, {0x018, 10},
+	{0x008, 10}, {0x067, 11}, {0x068, 11}, {0x06c, 11}, {0x037, 11}, {0x028, 11},
+	{0x017, 11}, {0x018, 11}, {0x0ca, 12}, {0x0cb, 12}, {0x0cc, 12}, {0x0cd, 12},
+	{0x068, 12}, {0x069, 12}, {0x06a, 12}, {0x06b, 12}, {0x0d2, 12}, {0x0d3, 12},
+	{0x0d4, 12}, {0x0d5, 12}, {0x0d6, 12}, {0x0d7, 12}, {0x06c, 12}, {0x06d, 12},
+	{0x0da, 12}, {0x0db, 12}, {0x054, 12}, {0x055, 12}, {0x056, 12}, {0x057, 12},
+	{0x064, 12}, {0x065, 12}, {0x052, 12}, {0x053, 12}, {0x024, 12}, {0x037, 12},
+	{0x038, 12}, {0x027, 12}, {0x028, 12}, {0x058, 12}, {0x059, 12}, {0x02b, 12},
+	{0x02c, 12}, {0x05a, 12}, {0x066, 12}, {0x067, 12}, {0x00f, 10}, {0x0c8, 12},
+	{0x0c9, 12}, {0x05b, 12}, {0x033, 12}, {0x034, 12}, {0x035, 12}, {0x06c, 13},
+	{0x06d, 13}, {0x04a, 13}, {0x04b, 13}, {0x04c, 13}, {0x04d, 13}, {0x072, 13},
+	{0x073, 13}, {0x074, 13}, {0x075, 13}, {0x076, 13}, {0x077, 13}, {0x052, 13},
+	{0x053, 13}, {0x054, 13}, {0x055, 13}, {0x05a, 13}, {0x05b, 13}, {0x064, 13},
+	{0x065, 13}, {0x008, 11}, {0x00c, 11}, {0x00d, 11}, {0x012, 12}, {0x013, 12},
+	{0x014, 12}, {0x015, 12}, {0x016, 12}, {0x017, 12}, {0x01c, 12}, {0x01d, 12},
+	{0x01e, 12}, {0x01f, 12},
+}
+
+// g4Encode compresses a bilevel image, given as rows of pixels set when
+// black, with CCITT Group 4 (T.6) coding.
+func g4Encode(rows [][]bool) []byte {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var w g4Writer
+
+	width := len(rows[0])
+
+	// The line above the first is white.
+	ref := make([]bool, width)
+
+	for _, line := range rows {
+		// a0 starts on an imaginary white pixel left of the line.
+		a0, black := -1, false
+
+		for a0 < width {
+			a1 := g4Change(line, a0)
+
+			// b1 is the first change on the reference line right of a0 to the
+			// opposite colour of a0, and b2 the change after it.
+			b1 := g4Change(ref, a0)
+			for b1 < width && ref[b1] == black {
+				b1 = g4Change(ref, b1)
+			}
+
+			b2 := g4Change(ref, b1)
+
+			switch d := a1 - b1; {
+			case b2 < a1:
+				w.write(g4Pass)
+				a0 = b2
+			case d >= -3 && d <= 3:
+				w.write(g4Vertical[d+3])
+				a0, black = a1, !black
+			default:
+				a2 := g4Change(line, a1)
+
+				start := a0
+				if start < 0 {
+					start = 0
+				}
+
+				w.write(g4Horizontal)
+				w.run(a1-start, black)
+				w.run(a2-a1, !black)
+				a0 = a2
+			}
+		}
+
+		ref = line
+	}
+
+	w.write(g4EOL)
+	w.write(g4EOL)
+
+	return w.flush()
+}
+
+// g4Change returns the position of the first pixel of line right of i with
+// a different colour from its left neighbour, or the line width if none.
+func g4Change(line []bool, i int) int {
+	for j := i + 1; j < len(line); j++ {
+		if j == 0 && line[j] || j > 0 && line[j] != line[j-1] {
+			return j
+		}
+	}
+
+	return len(line)
+}
+
+// g4Writer packs codes into bytes, most significant bit first.
+type g4Writer struct {
+	b   []byte
+	cur byte
+	n   uint
+}
+
+func (w *g4Writer) write(c g4Code) {
+	for i := c.n; i > 0; i-- {
+		w.cur = w.cur<<1 | byte(c.bits>>(i-1)&1)
+		w.n++
+
+		if w.n == 8 {
+			w.b = append(w.b, w.cur)
+			w.cur, w.n = 0, 0
+		}
+	}
+}
+
+// run writes a run of n white or black pixels as make-up codes followed by
+// a terminating code.
+func (w *g4Writer) run(n int, black bool) {
+	codes := g4WhiteCodes[:]
+	if black {
+		codes = g4BlackCodes[:]
+	}
+
+	for n >= 2560 {
+		w.write(codes[63+2560/64])
+		n -= 2560
+	}
+
+	if n >= 64 {
+		w.write(codes[63+n/64])
+		n %= 64
+	}
+
+	w.write(codes[n])
+}
+
+// flush returns the written bytes, padding the last with zero bits.
+func (w *g4Writer) flush() []byte {
+	if w.n > 0 {
+		w.b = append(w.b, w.cur<<(8-w.n))
+		w.cur, w.n = 0, 0
+	}
+
+	return w.b
+}
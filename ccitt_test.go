@@ -0,0 +1,122 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/ccitt"
+	"golang.org/x/image/tiff"
+)
+
+// runRow returns a row of width pixels made of alternating runs, white first.
+func runRow(width int, runs ...int) []bool {
+	row := make([]bool, width)
+	x, black := 0, false
+
+	for i := 0; x < width; i++ {
+		n := runs[i%len(runs)]
+		for j := 0; j < n && x < width; j++ {
+			row[x] = black
+			x++
+		}
+
+		black = !black
+	}
+
+	return row
+}
+
+func TestG4RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, width := range []int{1, 7, 29, 63, 64, 65, 129, 2559, 2560, 2561, 2625, 5201} {
+		rows := [][]bool{
+			runRow(width, width),
+			runRow(width, 0, width),
+			runRow(width, 1),
+			runRow(width, 63, 64, 65, 127, 128),
+			runRow(width, 2560, 2561),
+			runRow(width, 2625, 2624, 1),
+			runRow(width, 0, 2688, 3),
+		}
+
+		// Random runs, mostly short as in real codes.
+		for i := 0; i < 20; i++ {
+			runs := make([]int, 8)
+			for j := range runs {
+				runs[j] = 1 + r.Intn(8)
+				if r.Intn(4) == 0 {
+					runs[j] = 1 + r.Intn(3000)
+				}
+			}
+
+			rows = append(rows, runRow(width, runs...))
+		}
+
+		data := g4Encode(rows)
+
+		got, err := ioutil.ReadAll(ccitt.NewReader(bytes.NewReader(data), ccitt.MSB, ccitt.Group4, width, len(rows), nil))
+		if err != nil {
+			t.Fatalf("width %d: %v", width, err)
+		}
+
+		stride := (width + 7) / 8
+
+		if len(got) != stride*len(rows) {
+			t.Fatalf("width %d: decoded %d bytes, want %d", width, len(got), stride*len(rows))
+		}
+
+		for y, row := range rows {
+			for x, black := range row {
+				// Decoded bits are set for white pixels.
+				white := got[y*stride+x/8]&(0x80>>uint(x%8)) != 0
+
+				if white == black {
+					t.Fatalf("width %d: pixel (%d, %d) black = %v after decoding", width, x, y, !white)
+				}
+			}
+		}
+	}
+}
+
+func TestTIFFRoundTrip(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Odd sizes, and one wide enough for quiet zone rows longer than 2560.
+	for _, size := range []int{29, 101, 333, 2601} {
+		b, err := q.TIFF(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		img, err := tiff.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		want, err := q.bilevel(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if img.Bounds() != want.Bounds() {
+			t.Fatalf("size %d: bounds %v, want %v", size, img.Bounds(), want.Bounds())
+		}
+
+		for y := 0; y < want.Rect.Dy(); y++ {
+			for x := 0; x < want.Rect.Dx(); x++ {
+				black := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y == 0
+
+				if black != q.isBlack(want, x, y) {
+					t.Fatalf("size %d: pixel (%d, %d) black = %v", size, x, y, black)
+				}
+			}
+		}
+	}
+}
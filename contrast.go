@@ -0,0 +1,174 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Luminance contrast ratio below which scanners may not tell dark modules
+// from light ones.
+const minContrast = 3
+
+// ContrastWarning reports a foreground colour too close in luminance to the
+// background for reliable scanning.
+type ContrastWarning struct {
+	Color      color.Color
+	Background color.Color
+
+	// Contrast ratio as defined by WCAG 2, from 1 to 21.
+	Ratio float64
+}
+
+func (w *ContrastWarning) Error() string {
+	return fmt.Sprintf("low contrast ratio %.2f:1 between foreground %s and background %s",
+		w.Ratio, hexColor(w.Color), hexColor(w.Background))
+}
+
+// InvertedWarning reports a foreground colour lighter than the background.
+// Many scanners only read dark modules on a light background, so inverted
+// codes should be asked for with Inverted.
+type InvertedWarning struct {
+	Color      color.Color
+	Background color.Color
+}
+
+func (w *InvertedWarning) Error() string {
+	return fmt.Sprintf("foreground %s is lighter than background %s",
+		hexColor(w.Color), hexColor(w.Background))
+}
+
+// Warnings returns the problems found with the colours that may make the QR
+// Code hard to scan, as *ContrastWarning and *InvertedWarning values. They
+// don't prevent rendering unless Strict is set.
+func (q *QRCode) Warnings() []error {
+	var warnings []error
+
+	if g := q.ForegroundGradient; g != nil && len(g.Stops) > 0 {
+		for _, s := range g.Stops {
+			warnings = append(warnings, q.checkColor(s.Color)...)
+		}
+	} else {
+		warnings = append(warnings, q.checkColor(q.moduleColor())...)
+	}
+
+	for _, s := range []PatternStyle{q.FinderStyle, q.EyeStyle, q.AlignmentStyle} {
+		if s.Color != nil {
+			warnings = append(warnings, q.checkColor(s.Color)...)
+		}
+	}
+
+	return warnings
+}
+
+// validate returns the first colour problem in strict mode.
+func (q *QRCode) validate() error {
+	if !q.Strict {
+		return nil
+	}
+
+	if warnings := q.Warnings(); len(warnings) > 0 {
+		return warnings[0]
+	}
+
+	return nil
+}
+
+// checkColor returns the problems with drawing c over the background.
+func (q *QRCode) checkColor(c color.Color) []error {
+	// Contrast depends on what a transparent background is placed over.
+	if isTransparent(q.backgroundColor()) {
+		return nil
+	}
+
+	var warnings []error
+
+	if w := q.contrast(c); w != nil {
+		warnings = append(warnings, w)
+	}
+
+	if w := q.inverted(c); w != nil {
+		warnings = append(warnings, w)
+	}
+
+	return warnings
+}
+
+// contrast returns a warning if c lacks contrast with the background.
+func (q *QRCode) contrast(c color.Color) *ContrastWarning {
+	bg := q.backgroundColor()
+
+	r := contrastRatio(c, bg)
+	if r >= minContrast {
+		return nil
+	}
+
+	return &ContrastWarning{Color: c, Background: bg, Ratio: r}
+}
+
+// inverted returns a warning if c is lighter than the background, unless
+// Inverted asks for it.
+func (q *QRCode) inverted(c color.Color) *InvertedWarning {
+	if q.Inverted {
+		return nil
+	}
+
+	b := color.NRGBAModel.Convert(q.BackgroundColor).(color.NRGBA)
+	f := blend(color.NRGBAModel.Convert(c).(color.NRGBA), b)
+
+	if luminance(f) <= luminance(b) {
+		return nil
+	}
+
+	return &InvertedWarning{Color: c, Background: q.BackgroundColor}
+}
+
+// contrastRatio returns the WCAG 2 contrast ratio of fg drawn over bg.
+func contrastRatio(fg color.Color, bg color.Color) float64 {
+	b := color.NRGBAModel.Convert(bg).(color.NRGBA)
+	f := blend(color.NRGBAModel.Convert(fg).(color.NRGBA), b)
+
+	l1, l2 := luminance(f), luminance(b)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// blend returns f as seen over b, since a translucent foreground blends with
+// the background.
+func blend(f color.NRGBA, b color.NRGBA) color.NRGBA {
+	a := float64(f.A) / 0xff
+	mix := func(x uint8, y uint8) uint8 {
+		return uint8(math.Round(float64(x)*a + float64(y)*(1-a)))
+	}
+
+	return color.NRGBA{R: mix(f.R, b.R), G: mix(f.G, b.G), B: mix(f.B, b.B), A: 0xff}
+}
+
+// luminance returns the relative luminance of c, from 0 for black to 1 for
+// white.
+func luminance(c color.NRGBA) float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 0xff
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(c.R) + 0.7152*linear(c.G) + 0.0722*linear(c.B)
+}
+
+// hexColor formats c as #rrggbb, with an alpha byte when translucent.
+func hexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	if n.A != 0xff {
+		return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
@@ -0,0 +1,71 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DXF returns the QR Code as a DXF drawing in millimetres, width wide with
+// the quiet zone included, for laser engraving and CNC machining. Each dark
+// region is a closed polyline, with holes as polylines of their own. Width
+// and ModuleSize override width when set.
+func (q *QRCode) DXF(width Length) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	width, err := q.vectorWidth(width)
+	if err != nil {
+		return nil, err
+	}
+
+	n := q.symbol.size
+	m := width.Points() / Millimeter.Points() / float64(n)
+
+	var b strings.Builder
+
+	// Group codes and values alternate on their own lines. The header sets
+	// the drawing units to millimetres.
+	b.WriteString("0\nSECTION\n2\nHEADER\n9\n$INSUNITS\n70\n4\n0\nENDSEC\n")
+	b.WriteString("0\nSECTION\n2\nENTITIES\n")
+
+	for _, outline := range contours(q.markedBitmap()) {
+		// A closed polyline with its vertices following.
+		b.WriteString("0\nPOLYLINE\n8\nQRCODE\n66\n1\n70\n1\n")
+
+		// DXF has y pointing up.
+		for _, p := range outline {
+			fmt.Fprintf(&b, "0\nVERTEX\n8\nQRCODE\n10\n%s\n20\n%s\n",
+				svgNumber(float64(p.x)*m), svgNumber(float64(n-p.y)*m))
+		}
+
+		b.WriteString("0\nSEQEND\n8\nQRCODE\n")
+	}
+
+	b.WriteString("0\nENDSEC\n0\nEOF\n")
+
+	return []byte(b.String()), nil
+}
+
+// markedBitmap returns the modules marked by engraving or raised in 3D
+// models: the dark modules, or the light modules and quiet zone when
+// Inverted.
+func (q *QRCode) markedBitmap() [][]bool {
+	bitmap := q.symbol.bitmap()
+
+	if !q.Inverted {
+		return bitmap
+	}
+
+	inverted := make([][]bool, len(bitmap))
+
+	for y := range bitmap {
+		inverted[y] = make([]bool, len(bitmap[y]))
+
+		for x, v := range bitmap[y] {
+			inverted[y][x] = !v
+		}
+	}
+
+	return inverted
+}
@@ -0,0 +1,262 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+)
+
+// EPS returns the QR Code as an Encapsulated PostScript document drawn with
+// vector fills. The bounding box has the physical size of the equivalent
+// raster image, in points. color.CMYK colours are kept as CMYK.
+func (q *QRCode) EPS(size int) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	l := q.rasterSize(size)
+
+	// Page size in points, matching the physical size of the image.
+	width := q.physicalWidth(l.size)
+
+	// Points per pixel of the layout, and per module.
+	k := width / float64(l.size)
+	moduleSize := k * float64(l.scale)
+
+	if l.scale == 0 {
+		moduleSize = width / float64(l.modules)
+	}
+
+	var b bytes.Buffer
+
+	w := bufio.NewWriter(&b)
+
+	// Shadings need PostScript level 3.
+	level := 2
+	if q.ForegroundGradient != nil && len(q.ForegroundGradient.Stops) > 0 {
+		level = 3
+	}
+
+	fmt.Fprintf(w, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(w, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(width)))
+	fmt.Fprintf(w, "%%%%HiResBoundingBox: 0 0 %s %s\n", svgNumber(width), svgNumber(width))
+	fmt.Fprintf(w, "%%%%LanguageLevel: %d\n", level)
+	fmt.Fprintf(w, "%%%%EndComments\n")
+	fmt.Fprintf(w, "gsave\n")
+
+	if bg := q.backgroundColor(); !isTransparent(bg) {
+		fmt.Fprintf(w, "%s 0 0 %s %s rectfill\n", q.epsColor(bg), svgNumber(width), svgNumber(width))
+	}
+
+	// Draw in module coordinates from the top left corner of the first
+	// module, with y pointing down as in the other renderers.
+	o := k * float64(l.offset)
+	fmt.Fprintf(w, "%s %s translate %s %s scale\n",
+		svgNumber(o), svgNumber(width-o), svgNumber(moduleSize), svgNumber(-moduleSize))
+
+	for _, ly := range q.layers() {
+		if g := q.gradient(ly); g != nil {
+			fmt.Fprintf(w, "gsave newpath\n")
+			q.epsLayerPath(w, ly)
+			fmt.Fprintf(w, "clip newpath\n")
+
+			// Symbol area, quiet zone excluded.
+			g.epsShading(w, q, float64(q.symbol.quietZoneSize), float64(q.symbol.symbolSize))
+
+			fmt.Fprintf(w, "grestore\n")
+
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\n", q.epsColor(ly.paint(q)))
+
+		if ly.modules != nil && isSquare(ly.shape) {
+			for _, r := range rectangles(ly.modules) {
+				fmt.Fprintf(w, "%d %d %d %d rectfill\n", r.x, r.y, r.w, r.h)
+			}
+
+			ly.modules = nil
+		}
+
+		if ly.modules != nil || len(ly.paths) > 0 {
+			fmt.Fprintf(w, "newpath\n")
+			q.epsLayerPath(w, ly)
+			fmt.Fprintf(w, "fill\n")
+		}
+	}
+
+	if q.Logo != nil {
+		q.epsLogo(w)
+
+		for _, f := range q.logoOverlay() {
+			fmt.Fprintf(w, "%s newpath %s fill\n", q.epsColor(f.color), f.path.epsData())
+		}
+	}
+
+	fmt.Fprintf(w, "grestore\nshowpage\n%%%%EOF\n")
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return q.base64(b.Bytes(), "application/postscript"), nil
+}
+
+// epsLayerPath appends the outline of everything ly draws to the current
+// path.
+func (q *QRCode) epsLayerPath(w io.Writer, ly layer) {
+	paths := ly.paths
+
+	if ly.modules != nil && !isSquare(ly.shape) {
+		paths = append(paths[:len(paths):len(paths)], modulePaths(ly.modules, ly.shape)...)
+	} else if ly.modules != nil {
+		for _, r := range rectangles(ly.modules) {
+			var p Path
+			p.Rect(float64(r.x), float64(r.y), float64(r.w), float64(r.h))
+
+			paths = append(paths[:len(paths):len(paths)], p)
+		}
+	}
+
+	for _, p := range paths {
+		fmt.Fprintf(w, "%s\n", p.epsData())
+	}
+}
+
+// epsData returns PostScript path construction operators for the path.
+func (p Path) epsData() string {
+	operators := map[byte]string{'M': "moveto", 'L': "lineto", 'C': "curveto", 'Z': "closepath"}
+
+	var d strings.Builder
+
+	for i, op := range p.ops {
+		if i > 0 {
+			d.WriteByte(' ')
+		}
+
+		n := 1
+		switch op.op {
+		case 'C':
+			n = 3
+		case 'Z':
+			n = 0
+		}
+
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&d, "%s %s ", svgNumber(op.pts[i].x), svgNumber(op.pts[i].y))
+		}
+
+		d.WriteString(operators[op.op])
+	}
+
+	return d.String()
+}
+
+// epsColor returns the operator setting the fill colour to c. PostScript has
+// no transparency, so translucent colours are blended with the background.
+func (q *QRCode) epsColor(c color.Color) string {
+	if c, ok := c.(color.CMYK); ok {
+		return fmt.Sprintf("%s %s %s %s setcmykcolor",
+			epsComponent(c.C), epsComponent(c.M), epsComponent(c.Y), epsComponent(c.K))
+	}
+
+	n := color.NRGBAModel.Convert(q.over(c)).(color.NRGBA)
+
+	return fmt.Sprintf("%s %s %s setrgbcolor", epsComponent(n.R), epsComponent(n.G), epsComponent(n.B))
+}
+
+// epsComponent formats a colour component as a fraction from 0 to 1.
+func epsComponent(v uint8) string {
+	return svgNumber(float64(v) / 0xff)
+}
+
+// epsShading paints the gradient over an n x n symbol with its top left
+// corner at (o, o), blending its stops with a stitching function.
+func (g *Gradient) epsShading(w io.Writer, q *QRCode, o float64, n float64) {
+	stops := g.Stops
+
+	// The function domain runs from 0 to 1, with the end stops extended.
+	if stops[0].Offset > 0 {
+		stops = append([]GradientStop{{Offset: 0, Color: stops[0].Color}}, stops...)
+	}
+
+	if last := stops[len(stops)-1]; last.Offset < 1 || len(stops) == 1 {
+		stops = append(stops[:len(stops):len(stops)], GradientStop{Offset: 1, Color: last.Color})
+	}
+
+	rgb := func(c color.Color) string {
+		n := color.NRGBAModel.Convert(q.over(c)).(color.NRGBA)
+
+		return fmt.Sprintf("[%s %s %s]", epsComponent(n.R), epsComponent(n.G), epsComponent(n.B))
+	}
+
+	var functions, bounds, encode []string
+
+	for i := 1; i < len(stops); i++ {
+		functions = append(functions, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>",
+			rgb(stops[i-1].Color), rgb(stops[i].Color)))
+		encode = append(encode, "0 1")
+
+		if i > 1 {
+			bounds = append(bounds, svgNumber(stops[i-1].Offset))
+		}
+	}
+
+	if g.Kind == GradientRadial {
+		cx, cy, r := g.circle(n)
+
+		fmt.Fprintf(w, "<< /ShadingType 3 /Coords [%s %s 0 %s %s %s]\n",
+			svgNumber(o+cx), svgNumber(o+cy), svgNumber(o+cx), svgNumber(o+cy), svgNumber(r))
+	} else {
+		x1, y1, x2, y2 := g.line(n)
+
+		fmt.Fprintf(w, "<< /ShadingType 2 /Coords [%s %s %s %s]\n",
+			svgNumber(o+x1), svgNumber(o+y1), svgNumber(o+x2), svgNumber(o+y2))
+	}
+
+	fmt.Fprintf(w, "/ColorSpace /DeviceRGB /Extend [true true]\n")
+	fmt.Fprintf(w, "/Function << /FunctionType 3 /Domain [0 1] /Bounds [%s] /Encode [%s]\n/Functions [\n%s\n] >>\n",
+		strings.Join(bounds, " "), strings.Join(encode, " "), strings.Join(functions, "\n"))
+	fmt.Fprintf(w, ">> shfill\n")
+}
+
+// epsLogo draws the logo as an RGB image, blended with the background as
+// PostScript has no transparency.
+func (q *QRCode) epsLogo(w io.Writer) {
+	x, y, lw, lh := q.logoBox(1, 0, 0)
+
+	b := q.Logo.Bounds()
+
+	bg := q.backgroundColor()
+	if isTransparent(bg) {
+		bg = q.matte()
+	}
+
+	flat := image.NewRGBA(b)
+	draw.Draw(flat, b, image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(flat, b, q.Logo, b.Min, draw.Over)
+
+	// The image is mapped to the unit square, top row first.
+	fmt.Fprintf(w, "gsave %s %s translate %s %s scale\n", svgNumber(x), svgNumber(y), svgNumber(lw), svgNumber(lh))
+	fmt.Fprintf(w, "/DeviceRGB setcolorspace\n")
+	fmt.Fprintf(w, "<< /ImageType 1 /Width %d /Height %d /BitsPerComponent 8 /Decode [0 1 0 1 0 1]\n", b.Dx(), b.Dy())
+	fmt.Fprintf(w, "/ImageMatrix [%d 0 0 %d 0 0] /DataSource currentfile /ASCIIHexDecode filter >> image\n", b.Dx(), b.Dy())
+
+	for py := 0; py < b.Dy(); py++ {
+		row := flat.Pix[py*flat.Stride:]
+
+		for px := 0; px < b.Dx(); px++ {
+			fmt.Fprintf(w, "%02x%02x%02x", row[4*px], row[4*px+1], row[4*px+2])
+		}
+
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, ">\ngrestore\n")
+}
@@ -0,0 +1,82 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ESCPOSMode selects how ESC/POS output draws the QR Code.
+type ESCPOSMode int
+
+const (
+	// ESCPOSRaster sends the modules as a GS v 0 raster bit image, drawn
+	// exactly as the other renderers draw them.
+	ESCPOSRaster ESCPOSMode = iota
+	// ESCPOSNative uses the printer's own GS ( k QR Code commands. The
+	// printer encodes the content itself and ignores the drawing options.
+	ESCPOSNative
+)
+
+// ESCPOSOptions style ESC/POS output.
+type ESCPOSOptions struct {
+	Mode ESCPOSMode
+}
+
+// ESCPOS returns ESC/POS commands printing the QR Code on a receipt printer
+// with size dots per module. ESCPOSNative limits size to 1 to 16.
+func (q *QRCode) ESCPOS(size int, opts ESCPOSOptions) ([]byte, error) {
+	if size < 1 || opts.Mode == ESCPOSNative && size > 16 {
+		return nil, fmt.Errorf("ESC/POS module size %d out of range", size)
+	}
+
+	if opts.Mode == ESCPOSNative {
+		return q.escposNative(size)
+	}
+
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	img := q.bilevelLayout(q.layout(size, ScaleModule))
+
+	n := img.Bounds().Dx()
+	bytesPerRow := (n + 7) / 8
+
+	var b bytes.Buffer
+
+	// GS v 0 in normal density, then the width in bytes and the height in
+	// dots, little endian.
+	b.Write([]byte{0x1d, 'v', '0', 0, byte(bytesPerRow), byte(bytesPerRow >> 8), byte(n), byte(n >> 8)})
+
+	row := make([]byte, bytesPerRow)
+
+	for y := 0; y < n; y++ {
+		q.packBits(row, img, y, false, false)
+		b.Write(row)
+	}
+
+	return b.Bytes(), nil
+}
+
+// escposNative returns the GS ( k commands selecting model 2, the module
+// size and the error correction level, storing the content and printing it.
+func (q *QRCode) escposNative(size int) ([]byte, error) {
+	data := []byte(q.content)
+
+	// The stored data length includes the function selection bytes.
+	k := len(data) + 3
+	if k > 0xffff {
+		return nil, fmt.Errorf("content too long for ESC/POS (%d bytes)", len(data))
+	}
+
+	var b bytes.Buffer
+
+	b.Write([]byte{0x1d, '(', 'k', 4, 0, 49, 65, 50, 0})
+	b.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 67, byte(size)})
+	b.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 69, byte(48 + q.level)})
+	b.Write([]byte{0x1d, '(', 'k', byte(k), byte(k >> 8), 49, 80, 48})
+	b.Write(data)
+	b.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 81, 48})
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,71 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestESCPOSNative(t *testing.T) {
+	q, err := New("https://example.org", High)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := q.ESCPOS(6, ESCPOSOptions{Mode: ESCPOSNative})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "native.escpos", b)
+
+	// The store command carries the content after its function selection.
+	store := append([]byte{0x1d, '(', 'k', 22, 0, 49, 80, 48}, "https://example.org"...)
+	if !bytes.Contains(b, store) {
+		t.Errorf("no store command for the content in %q", b)
+	}
+
+	if _, err := q.ESCPOS(17, ESCPOSOptions{Mode: ESCPOSNative}); err == nil {
+		t.Error("module size 17 accepted")
+	}
+}
+
+func TestESCPOSRaster(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := q.ESCPOS(3, ESCPOSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "raster.escpos", b)
+
+	// GS v 0, then the width in bytes and the height in dots.
+	if len(b) < 8 || !bytes.Equal(b[:4], []byte{0x1d, 'v', '0', 0}) {
+		t.Fatalf("no GS v 0 header in %q", b)
+	}
+
+	bytesPerRow := int(b[4]) | int(b[5])<<8
+	height := int(b[6]) | int(b[7])<<8
+
+	img := q.bilevelLayout(q.layout(3, ScaleModule))
+	if n := img.Rect.Dx(); bytesPerRow != (n+7)/8 || height != n {
+		t.Fatalf("header %d bytes x %d dots for a %d dot image", bytesPerRow, height, n)
+	}
+
+	if len(b) != 8+bytesPerRow*height {
+		t.Fatalf("%d bytes of image data, want %d", len(b)-8, bytesPerRow*height)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			dot := b[8+y*bytesPerRow+x/8]&(0x80>>uint(x%8)) != 0
+
+			if dot != q.isBlack(img, x, y) {
+				t.Fatalf("dot (%d, %d) printed = %v", x, y, dot)
+			}
+		}
+	}
+}
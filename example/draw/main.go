@@ -0,0 +1,54 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+
+	qrcode "github.com/RashadAnsari/go-qrcode"
+)
+
+func main() {
+	qr, err := qrcode.New("https://rashadansari.github.io", qrcode.High)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	qr.Shape = qrcode.ShapeRounded
+	qr.FinderStyle = qrcode.PatternStyle{Shape: qrcode.PatternRounded}
+	qr.EyeStyle = qrcode.PatternStyle{Shape: qrcode.PatternCircle}
+	qr.ForegroundGradient = qrcode.LinearGradient(45,
+		qrcode.GradientStop{Offset: 0, Color: color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff}},
+		qrcode.GradientStop{Offset: 1, Color: color.RGBA{R: 0x88, G: 0x0e, B: 0x4f, A: 0xff}})
+	qr.Frame = qrcode.Frame{
+		Style:  qrcode.FrameBanner,
+		Radius: 2,
+		Bottom: qrcode.Caption{Text: "Scan me"},
+	}
+
+	for _, w := range qr.Warnings() {
+		log.Println(w)
+	}
+
+	// A poster with the code in its lower right quarter.
+	poster := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	draw.Draw(poster, poster.Bounds(), image.NewUniform(color.RGBA{R: 0xff, G: 0xf8, B: 0xe1, A: 0xff}), image.Point{}, draw.Src)
+
+	if err := qr.Draw(poster, image.Rect(450, 250, 750, 550)); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	f, err := os.Create("poster.png")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	defer f.Close()
+
+	if err := png.Encode(f, poster); err != nil {
+		log.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	qrcode "github.com/RashadAnsari/go-qrcode"
+)
+
+func main() {
+	sheet := qrcode.Sheet{
+		Template:  qrcode.Avery5160,
+		Padding:   0.1 * qrcode.Inch,
+		CropMarks: true,
+	}
+
+	for i := 1; i <= 12; i++ {
+		qr, err := qrcode.New(fmt.Sprintf("https://example.org/asset/%d", i), qrcode.Medium)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		sheet.Labels = append(sheet.Labels, qrcode.Label{
+			Code: qr,
+			Text: fmt.Sprintf("Asset %d\nProperty of Example Ltd", i),
+		})
+	}
+
+	bytes, err := sheet.PDF()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if err := os.WriteFile("labels.pdf", bytes, os.FileMode(0644)); err != nil {
+		log.Fatal(err.Error())
+	}
+}
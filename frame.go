@@ -0,0 +1,351 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// FrameStyle selects the border drawn around a framed QR Code.
+type FrameStyle int
+
+const (
+	// FrameNone draws no border. Padding, captions and the corner radius
+	// still apply.
+	FrameNone FrameStyle = iota
+	// FrameBorder outlines the symbol and its captions.
+	FrameBorder
+	// FrameBanner fills the border and the caption bands with the frame
+	// colour, and writes the captions in the background colour.
+	FrameBanner
+)
+
+// Frame decorates raster, SVG and PDF output with a border and captions,
+// making the canvas larger than the symbol, and taller than wide when
+// captioned. Lengths are in modules.
+type Frame struct {
+	Style FrameStyle
+
+	// Width of the border, 1 if 0 with a style set.
+	Border float64
+
+	// Space between the quiet zone and the captions or border.
+	Padding float64
+
+	// Radius of the outer corners. The canvas outside them is transparent.
+	Radius float64
+
+	// Colour of the border, the module colour if nil.
+	Color color.Color
+
+	// Captions above and below the symbol.
+	Top    Caption
+	Bottom Caption
+}
+
+// Caption is a line of text written in the bundled Go Regular font.
+type Caption struct {
+	Text string
+
+	// Font size, 3 if 0. Text wider than the symbol is shrunk to fit.
+	Size float64
+
+	// Colour of the text, that of the frame if nil, or the background colour
+	// with FrameBanner.
+	Color color.Color
+}
+
+// isSet reports whether f draws anything.
+func (f Frame) isSet() bool {
+	return f.Style != FrameNone || f.Padding > 0 || f.Radius > 0 || f.Top.Text != "" || f.Bottom.Text != ""
+}
+
+type frameLayout struct {
+	// Margins around the symbol, in modules.
+	left, top, bottom float64
+
+	// Fills drawn under the symbol, in modules from its top left corner.
+	fills []pathFill
+}
+
+// frame lays out the frame around a symbol s modules wide, quiet zone
+// included.
+func (q *QRCode) frame(s float64) (frameLayout, error) {
+	var fl frameLayout
+
+	f := q.Frame
+	if !f.isSet() {
+		return fl, nil
+	}
+
+	border := 0.0
+	if f.Style != FrameNone {
+		border = math.Max(f.Border, 0)
+		if border == 0 {
+			border = 1
+		}
+	}
+
+	padding := math.Max(f.Padding, 0)
+
+	frameColor := f.Color
+	if frameColor == nil {
+		frameColor = q.moduleColor()
+	}
+
+	textColor := frameColor
+	if f.Style == FrameBanner {
+		textColor = q.backgroundColor()
+		if isTransparent(textColor) {
+			textColor = q.matte()
+		}
+	}
+
+	top, err := captionPath(f.Top, s)
+	if err != nil {
+		return fl, err
+	}
+
+	bottom, err := captionPath(f.Bottom, s)
+	if err != nil {
+		return fl, err
+	}
+
+	// From the outside in: border, caption band, padding, then the symbol.
+	fl.left = border + padding
+	fl.top = border + top.height + padding
+	fl.bottom = border + bottom.height + padding
+
+	x, y := -fl.left, -fl.top
+	w, h := s+2*fl.left, s+fl.top+fl.bottom
+	r := math.Min(math.Max(f.Radius, 0), math.Min(w, h)/2)
+	inner := math.Max(r-border, 0)
+
+	var outline Path
+	outline.RoundedRect(x, y, w, h, r, r, r, r)
+
+	switch f.Style {
+	case FrameBanner:
+		var light Path
+		light.RoundedRect(-padding, -padding, s+2*padding, s+2*padding, inner, inner, inner, inner)
+
+		fl.fills = append(fl.fills, pathFill{outline, frameColor}, pathFill{light, q.backgroundColor()})
+	case FrameBorder:
+		if bg := q.backgroundColor(); !isTransparent(bg) {
+			fl.fills = append(fl.fills, pathFill{outline, bg})
+		}
+
+		var hole Path
+		hole.RoundedRect(x+border, y+border, w-2*border, h-2*border, inner, inner, inner, inner)
+
+		ring := outline
+		ring.Append(hole.reversed(), 0, 0)
+
+		fl.fills = append(fl.fills, pathFill{ring, frameColor})
+	default:
+		if bg := q.backgroundColor(); !isTransparent(bg) {
+			fl.fills = append(fl.fills, pathFill{outline, bg})
+		}
+	}
+
+	for _, c := range []struct {
+		caption Caption
+		text    caption
+		y       float64
+	}{
+		{f.Top, top, y + border},
+		{f.Bottom, bottom, s + padding},
+	} {
+		if len(c.text.path.ops) == 0 {
+			continue
+		}
+
+		col := c.caption.Color
+		if col == nil {
+			col = textColor
+		}
+
+		var p Path
+		p.Append(c.text.path, (s-c.text.width)/2, c.y+c.text.baseline)
+
+		fl.fills = append(fl.fills, pathFill{p, col})
+	}
+
+	return fl, nil
+}
+
+// caption is a line of text laid out from the start of its baseline.
+type caption struct {
+	path Path
+
+	width, height float64
+
+	// Distance from the top of the band to the baseline.
+	baseline float64
+}
+
+var captionFont struct {
+	once sync.Once
+	font *sfnt.Font
+	err  error
+}
+
+// captionPath lays out c with its band height, shrinking the text to fit
+// maxWidth.
+func captionPath(c Caption, maxWidth float64) (caption, error) {
+	var t caption
+
+	if c.Text == "" {
+		return t, nil
+	}
+
+	captionFont.once.Do(func() {
+		captionFont.font, captionFont.err = sfnt.Parse(goregular.TTF)
+	})
+
+	f, err := captionFont.font, captionFont.err
+	if err != nil {
+		return t, err
+	}
+
+	var buf sfnt.Buffer
+
+	// Glyphs are loaded at one pixel per font unit, then scaled.
+	ppem := fixed.I(int(f.UnitsPerEm()))
+
+	var (
+		glyphs []sfnt.GlyphIndex
+		pen    []fixed.Int26_6
+	)
+
+	x := fixed.Int26_6(0)
+
+	for _, r := range c.Text {
+		g, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			return t, err
+		}
+
+		if len(glyphs) > 0 {
+			// Fonts without kerning report an error.
+			if k, err := f.Kern(&buf, glyphs[len(glyphs)-1], g, ppem, font.HintingNone); err == nil {
+				x += k
+			}
+		}
+
+		glyphs = append(glyphs, g)
+		pen = append(pen, x)
+
+		advance, err := f.GlyphAdvance(&buf, g, ppem, font.HintingNone)
+		if err != nil {
+			return t, err
+		}
+
+		x += advance
+	}
+
+	size := c.Size
+	if size <= 0 {
+		size = 3
+	}
+
+	scale := size / float64(f.UnitsPerEm())
+
+	if w := float64(x) / 64 * scale; w > maxWidth && w > 0 {
+		scale *= maxWidth / w
+	}
+
+	at := func(p fixed.Point26_6, dx fixed.Int26_6) vec {
+		return vec{x: float64(p.X+dx) / 64 * scale, y: float64(p.Y) / 64 * scale}
+	}
+
+	for i, g := range glyphs {
+		segments, err := f.LoadGlyph(&buf, g, ppem, nil)
+		if err != nil {
+			return t, err
+		}
+
+		var last vec
+
+		for _, seg := range segments {
+			switch seg.Op {
+			case sfnt.SegmentOpMoveTo:
+				if len(t.path.ops) > 0 && t.path.ops[len(t.path.ops)-1].op != 'Z' {
+					t.path.Close()
+				}
+
+				last = at(seg.Args[0], pen[i])
+				t.path.MoveTo(last.x, last.y)
+			case sfnt.SegmentOpLineTo:
+				last = at(seg.Args[0], pen[i])
+				t.path.LineTo(last.x, last.y)
+			case sfnt.SegmentOpQuadTo:
+				// Quadratic curves are raised to cubic ones with the control
+				// point two thirds of the way from each end.
+				c, p := at(seg.Args[0], pen[i]), at(seg.Args[1], pen[i])
+				t.path.CubeTo(last.x+(c.x-last.x)*2/3, last.y+(c.y-last.y)*2/3,
+					p.x+(c.x-p.x)*2/3, p.y+(c.y-p.y)*2/3, p.x, p.y)
+				last = p
+			case sfnt.SegmentOpCubeTo:
+				c1, c2, p := at(seg.Args[0], pen[i]), at(seg.Args[1], pen[i]), at(seg.Args[2], pen[i])
+				t.path.CubeTo(c1.x, c1.y, c2.x, c2.y, p.x, p.y)
+				last = p
+			}
+		}
+
+		if len(t.path.ops) > 0 && t.path.ops[len(t.path.ops)-1].op != 'Z' {
+			t.path.Close()
+		}
+	}
+
+	m, err := f.Metrics(&buf, ppem, font.HintingNone)
+	if err != nil {
+		return t, err
+	}
+
+	ascent := float64(m.Ascent) / 64 * scale
+	descent := float64(m.Descent) / 64 * scale
+
+	// The band leaves a fifth of the text height above and below it.
+	gap := (ascent + descent) / 5
+
+	t.width = float64(x) / 64 * scale
+	t.height = ascent + descent + 2*gap
+	t.baseline = gap + ascent
+
+	return t, nil
+}
+
+// decorate draws the frame around img, the symbol laid out by l.
+func (q *QRCode) decorate(img image.Image, l rasterLayout) (image.Image, error) {
+	m := l.moduleSize()
+
+	fl, err := q.frame(float64(l.size) / m)
+	if err != nil || !q.Frame.isSet() {
+		return img, err
+	}
+
+	left := int(math.Round(fl.left * m))
+	top := int(math.Round(fl.top * m))
+	bottom := int(math.Round(fl.bottom * m))
+
+	// Text and rounded corners are smoothed unless anti-aliasing is off.
+	k := supersampling
+	if q.Antialias == AntialiasNever {
+		k = 1
+	}
+
+	w, h := l.size+2*left, l.size+top+bottom
+	canvas := supersample(w, h, k, rgbaFiller(fl.fills, m*float64(k), float64(left*k), float64(top*k)))
+
+	draw.Draw(canvas, img.Bounds().Add(image.Pt(left, top)), img, img.Bounds().Min, draw.Over)
+
+	return canvas, nil
+}
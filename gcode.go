@@ -0,0 +1,147 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GCodeMode selects the toolpath of G-code output.
+type GCodeMode int
+
+const (
+	// GCodeRaster fills the dark modules with horizontal lines Step apart,
+	// alternating direction.
+	GCodeRaster GCodeMode = iota
+	// GCodeVector traces the outlines of the dark regions and their holes.
+	GCodeVector
+)
+
+// GCodeOptions set the toolpath and laser settings of G-code output.
+// Defaults are used for values not set.
+type GCodeOptions struct {
+	Mode GCodeMode
+
+	// Feed rate of engraving moves in millimetres per minute.
+	Feed float64
+
+	// Laser power, as the value of the S word.
+	Power float64
+
+	// Distance between raster lines.
+	Step Length
+}
+
+// Defaults of the G-code options.
+const (
+	defaultGCodeFeed  = 1000
+	defaultGCodePower = 1000
+	defaultGCodeStep  = Millimeter / 10
+)
+
+// GCode returns a G-code program engraving the QR Code with a laser, width
+// wide with the quiet zone included. Coordinates are in millimetres from the
+// bottom left corner of the quiet zone. Width and ModuleSize override width
+// when set.
+//
+// The laser runs in dynamic power mode (M4): it is off during rapid moves
+// and at opts.Power during engraving moves at opts.Feed.
+func (q *QRCode) GCode(width Length, opts GCodeOptions) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	width, err := q.vectorWidth(width)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, power, step := opts.Feed, opts.Power, opts.Step
+
+	if feed <= 0 {
+		feed = defaultGCodeFeed
+	}
+
+	if power <= 0 {
+		power = defaultGCodePower
+	}
+
+	if step <= 0 {
+		step = defaultGCodeStep
+	}
+
+	n := q.symbol.size
+
+	// Sizes in millimetres.
+	size := width.Points() / Millimeter.Points()
+	m := size / float64(n)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "G21\nG90\nM4 S0\nF%s\n", svgNumber(feed))
+
+	// move writes a rapid move (G0) or an engraving move (G1) to the module
+	// corner (x, y), y pointing down as in the bitmap.
+	move := func(g int, x float64, y float64) {
+		fmt.Fprintf(&b, "G%d X%s Y%s", g, svgNumber(x*m), svgNumber(size-y*m))
+
+		if g == 1 {
+			fmt.Fprintf(&b, " S%s", svgNumber(power))
+		}
+
+		b.WriteString("\n")
+	}
+
+	bitmap := q.markedBitmap()
+
+	if opts.Mode == GCodeVector {
+		for _, outline := range contours(bitmap) {
+			move(0, float64(outline[0].x), float64(outline[0].y))
+
+			for i := range outline {
+				p := outline[(i+1)%len(outline)]
+				move(1, float64(p.x), float64(p.y))
+			}
+		}
+	} else {
+		lines := int(size / (step.Points() / Millimeter.Points()))
+		reverse := false
+
+		for i := 0; i < lines; i++ {
+			// Lines run through the middle of their step.
+			y := (float64(i) + 0.5) * float64(n) / float64(lines)
+			row := bitmap[int(y)]
+
+			var runs [][2]int
+
+			for x := 0; x < n; x++ {
+				if row[x] && (x == 0 || !row[x-1]) {
+					runs = append(runs, [2]int{x, x + 1})
+				} else if row[x] {
+					runs[len(runs)-1][1] = x + 1
+				}
+			}
+
+			if len(runs) == 0 {
+				continue
+			}
+
+			for j := range runs {
+				x0, x1 := runs[j][0], runs[j][1]
+
+				if reverse {
+					r := runs[len(runs)-1-j]
+					x0, x1 = r[1], r[0]
+				}
+
+				move(0, float64(x0), y)
+				move(1, float64(x1), y)
+			}
+
+			reverse = !reverse
+		}
+	}
+
+	b.WriteString("M5\nG0 X0 Y0\nM2\n")
+
+	return []byte(b.String()), nil
+}
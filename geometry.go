@@ -0,0 +1,186 @@
+package qrcode
+
+// moduleRect is an axis aligned block of modules, in module coordinates.
+type moduleRect struct {
+	x, y int
+	w, h int
+}
+
+// rectangles covers the set modules of bitmap with as few rectangles as a
+// greedy merge finds: horizontal runs are found first, and each run is then
+// extended downwards over identical runs in the following rows.
+func rectangles(bitmap [][]bool) []moduleRect {
+	var rects []moduleRect
+
+	// Modules already covered by a rectangle.
+	used := make([][]bool, len(bitmap))
+	for y := range bitmap {
+		used[y] = make([]bool, len(bitmap[y]))
+	}
+
+	for y := range bitmap {
+		for x := 0; x < len(bitmap[y]); x++ {
+			if !bitmap[y][x] || used[y][x] {
+				continue
+			}
+
+			w := 1
+			for x+w < len(bitmap[y]) && bitmap[y][x+w] && !used[y][x+w] {
+				w++
+			}
+
+			h := 1
+			for y+h < len(bitmap) && isRun(bitmap[y+h], used[y+h], x, w) {
+				h++
+			}
+
+			for j := y; j < y+h; j++ {
+				for i := x; i < x+w; i++ {
+					used[j][i] = true
+				}
+			}
+
+			rects = append(rects, moduleRect{x: x, y: y, w: w, h: h})
+
+			x += w - 1
+		}
+	}
+
+	return rects
+}
+
+// isRun reports whether row holds exactly an unused run of w set modules
+// starting at x, bounded by unset or used modules on both sides.
+func isRun(row []bool, used []bool, x int, w int) bool {
+	for i := x; i < x+w; i++ {
+		if !row[i] || used[i] {
+			return false
+		}
+	}
+
+	if x > 0 && row[x-1] && !used[x-1] {
+		return false
+	}
+
+	if x+w < len(row) && row[x+w] && !used[x+w] {
+		return false
+	}
+
+	return true
+}
+
+// modulePoint is a corner between modules, in module coordinates.
+type modulePoint struct {
+	x, y int
+}
+
+// contours traces the outlines of the set modules of bitmap. Each outline is
+// a closed polygon with a corner at every change of direction. Outlines run
+// clockwise around set modules (y pointing down) and anticlockwise around
+// holes, so they fill correctly under both the nonzero and evenodd rules.
+// Modules touching only at a corner get separate outlines.
+func contours(bitmap [][]bool) [][]modulePoint {
+	return trace(bitmap, false)
+}
+
+// trace traces the outlines of the set modules of bitmap like contours, with
+// modules touching only at a corner joined into one outline if join is set.
+func trace(bitmap [][]bool, join bool) [][]modulePoint {
+	n := len(bitmap)
+
+	set := func(x int, y int) bool {
+		return x >= 0 && y >= 0 && y < n && x < len(bitmap[y]) && bitmap[y][x]
+	}
+
+	// Boundary edges keep set modules on their right, indexed by start corner.
+	// A corner starts at most two edges, when set modules meet diagonally.
+	type edge struct {
+		to   modulePoint
+		used bool
+	}
+
+	stride := n + 1
+	edges := make([][]edge, stride*stride)
+
+	add := func(from modulePoint, to modulePoint) {
+		i := from.y*stride + from.x
+		edges[i] = append(edges[i], edge{to: to})
+	}
+
+	for y := 0; y < n; y++ {
+		for x := 0; x < len(bitmap[y]); x++ {
+			if !bitmap[y][x] {
+				continue
+			}
+
+			if !set(x, y-1) {
+				add(modulePoint{x, y}, modulePoint{x + 1, y})
+			}
+
+			if !set(x+1, y) {
+				add(modulePoint{x + 1, y}, modulePoint{x + 1, y + 1})
+			}
+
+			if !set(x, y+1) {
+				add(modulePoint{x + 1, y + 1}, modulePoint{x, y + 1})
+			}
+
+			if !set(x-1, y) {
+				add(modulePoint{x, y + 1}, modulePoint{x, y})
+			}
+		}
+	}
+
+	var result [][]modulePoint
+
+	for i := range edges {
+		for j := range edges[i] {
+			if edges[i][j].used {
+				continue
+			}
+
+			from := modulePoint{x: i % stride, y: i / stride}
+			e := &edges[i][j]
+
+			var outline []modulePoint
+
+			for !e.used {
+				e.used = true
+				dx, dy := e.to.x-from.x, e.to.y-from.y
+
+				// Keep corners only where the direction changes.
+				if len(outline) < 2 || !collinear(outline[len(outline)-2], outline[len(outline)-1], from) {
+					outline = append(outline, from)
+				} else {
+					outline[len(outline)-1] = from
+				}
+
+				from = e.to
+				next := edges[from.y*stride+from.x]
+				e = &next[0]
+
+				// Turn right at diagonal corners, keeping the outline tight, or
+				// left to join the modules.
+				if len(next) > 1 && (next[1].to == modulePoint{x: from.x - dy, y: from.y + dx}) != join {
+					e = &next[1]
+				}
+			}
+
+			if len(outline) > 2 && collinear(outline[len(outline)-2], outline[len(outline)-1], outline[0]) {
+				outline = outline[:len(outline)-1]
+			}
+
+			if len(outline) > 2 && collinear(outline[len(outline)-1], outline[0], outline[1]) {
+				outline = outline[1:]
+			}
+
+			result = append(result, outline)
+		}
+	}
+
+	return result
+}
+
+func collinear(a modulePoint, b modulePoint, c modulePoint) bool {
+	return (b.x-a.x)*(c.y-b.y) == (b.y-a.y)*(c.x-b.x)
+}
@@ -0,0 +1,32 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+func (q *QRCode) GIF(size int) ([]byte, error) {
+	img, err := q.image(size)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	// Images needing more than a palette are reduced to 256 colours without
+	// dithering, which would speckle module edges. GIF only has on/off
+	// transparency, so they are flattened first.
+	opts := &gif.Options{NumColors: 256, Drawer: draw.Src}
+
+	if _, ok := img.(*image.Paletted); !ok {
+		img = q.flatten(img)
+	}
+
+	if err := gif.Encode(&b, img, opts); err != nil {
+		return nil, err
+	}
+
+	return q.base64(b.Bytes(), "image/gif"), nil
+}
@@ -4,5 +4,6 @@ go 1.13
 
 require (
 	github.com/ajstarks/svgo v0.0.0-20210927141636-6d70534b1098
-	github.com/signintech/gopdf v0.10.3
+	github.com/signintech/gopdf v0.36.0
+	golang.org/x/image v0.18.0
 )
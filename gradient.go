@@ -0,0 +1,270 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/signintech/gopdf"
+)
+
+// GradientKind selects the geometry of a Gradient.
+type GradientKind int
+
+const (
+	// GradientLinear blends colours along a straight line.
+	GradientLinear GradientKind = iota
+	// GradientRadial blends colours outwards from a centre.
+	GradientRadial
+)
+
+// GradientStop is a colour at an offset, from 0 to 1, along a Gradient.
+type GradientStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Gradient fills the foreground with colours blended between stops. Offsets
+// span the symbol, quiet zone excluded: a linear gradient runs from corner to
+// corner along its angle, and a radial gradient reaches offset 1 at the corner
+// farthest from its centre.
+type Gradient struct {
+	Kind GradientKind
+
+	// Direction of a linear gradient in degrees, clockwise from left to right.
+	Angle float64
+
+	// Centre of a radial gradient, as fractions of the symbol width from its
+	// top left corner.
+	CenterX float64
+	CenterY float64
+
+	// Stops in increasing offset order.
+	Stops []GradientStop
+}
+
+// LinearGradient returns a linear gradient at angle degrees, clockwise from
+// left to right.
+func LinearGradient(angle float64, stops ...GradientStop) *Gradient {
+	return &Gradient{Kind: GradientLinear, Angle: angle, Stops: stops}
+}
+
+// RadialGradient returns a radial gradient centred at (cx, cy), as fractions
+// of the symbol width.
+func RadialGradient(cx float64, cy float64, stops ...GradientStop) *Gradient {
+	return &Gradient{Kind: GradientRadial, CenterX: cx, CenterY: cy, Stops: stops}
+}
+
+// gradientID identifies the foreground gradient in SVG output.
+const gradientID = "qrcode-gradient"
+
+// gradient returns the gradient filling ly, or nil if it has a plain colour.
+// Pattern parts with their own colour keep it.
+func (q *QRCode) gradient(ly layer) *Gradient {
+	g := q.ForegroundGradient
+
+	if ly.color != nil || g == nil || len(g.Stops) == 0 {
+		return nil
+	}
+
+	return g
+}
+
+// at returns the colour at offset t.
+func (g *Gradient) at(t float64) color.NRGBA {
+	first, last := g.Stops[0], g.Stops[len(g.Stops)-1]
+
+	if t <= first.Offset {
+		return color.NRGBAModel.Convert(first.Color).(color.NRGBA)
+	}
+
+	if t >= last.Offset {
+		return color.NRGBAModel.Convert(last.Color).(color.NRGBA)
+	}
+
+	i := 1
+	for g.Stops[i].Offset < t {
+		i++
+	}
+
+	a, b := g.Stops[i-1], g.Stops[i]
+
+	f := 0.0
+	if b.Offset > a.Offset {
+		f = (t - a.Offset) / (b.Offset - a.Offset)
+	}
+
+	// Blending premultiplied colours keeps a transparent stop from tinting
+	// its neighbour.
+	ca := color.RGBA64Model.Convert(a.Color).(color.RGBA64)
+	cb := color.RGBA64Model.Convert(b.Color).(color.RGBA64)
+
+	mix := func(x uint16, y uint16) uint16 {
+		return uint16(math.Round(float64(x)*(1-f) + float64(y)*f))
+	}
+
+	c := color.RGBA64{R: mix(ca.R, cb.R), G: mix(ca.G, cb.G), B: mix(ca.B, cb.B), A: mix(ca.A, cb.A)}
+
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+// line returns the start and end of a linear gradient over an n x n symbol
+// with its top left corner at the origin.
+func (g *Gradient) line(n float64) (x1 float64, y1 float64, x2 float64, y2 float64) {
+	a := g.Angle * math.Pi / 180
+	dx, dy := math.Cos(a), math.Sin(a)
+
+	// Half the length, so the ends pass through opposite corners.
+	h := n / 2 * (math.Abs(dx) + math.Abs(dy))
+
+	return n/2 - dx*h, n/2 - dy*h, n/2 + dx*h, n/2 + dy*h
+}
+
+// circle returns the centre and radius of a radial gradient over an n x n
+// symbol with its top left corner at the origin.
+func (g *Gradient) circle(n float64) (cx float64, cy float64, r float64) {
+	cx, cy = g.CenterX*n, g.CenterY*n
+
+	r = math.Max(math.Hypot(cx, cy), math.Hypot(n-cx, cy))
+	r = math.Max(r, math.Max(math.Hypot(cx, n-cy), math.Hypot(n-cx, n-cy)))
+
+	return cx, cy, r
+}
+
+// offset returns the gradient offset at (x, y) of an n x n symbol.
+func (g *Gradient) offset(x float64, y float64, n float64) float64 {
+	if g.Kind == GradientRadial {
+		cx, cy, r := g.circle(n)
+		if r == 0 {
+			return 0
+		}
+
+		return math.Hypot(x-cx, y-cy) / r
+	}
+
+	x1, y1, x2, y2 := g.line(n)
+	dx, dy := x2-x1, y2-y1
+
+	return ((x-x1)*dx + (y-y1)*dy) / (dx*dx + dy*dy)
+}
+
+// svgGradient writes the gradient definition for an n x n symbol whose top
+// left corner is at (o, o) in user space.
+func (g *Gradient) svgGradient(w io.Writer, o float64, n float64) error {
+	var err error
+
+	if g.Kind == GradientRadial {
+		cx, cy, r := g.circle(n)
+
+		_, err = fmt.Fprintf(w, `<defs><radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s">`,
+			gradientID, svgNumber(o+cx), svgNumber(o+cy), svgNumber(r))
+	} else {
+		x1, y1, x2, y2 := g.line(n)
+
+		_, err = fmt.Fprintf(w, `<defs><linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">`,
+			gradientID, svgNumber(o+x1), svgNumber(o+y1), svgNumber(o+x2), svgNumber(o+y2))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range g.Stops {
+		c := color.NRGBAModel.Convert(s.Color).(color.NRGBA)
+
+		_, err = fmt.Fprintf(w, `<stop offset="%s" stop-color="#%02x%02x%02x" stop-opacity="%.2f" />`,
+			svgNumber(s.Offset), c.R, c.G, c.B, float64(c.A)/0xff)
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.Kind == GradientRadial {
+		_, err = fmt.Fprint(w, "</radialGradient></defs>\n")
+	} else {
+		_, err = fmt.Fprint(w, "</linearGradient></defs>\n")
+	}
+
+	return err
+}
+
+// Number of bands a gradient is split into in PDF output, which has no
+// native shading support in the PDF library.
+const pdfGradientBands = 128
+
+// pdfGradient paints the gradient in bands over an n x n symbol with its top
+// left corner at (x, y), clipped to the outline made of points.
+func (g *Gradient) pdfGradient(pdf *gopdf.GoPdf, points []gopdf.Point, x float64, y float64, n float64) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	pdf.SaveGraphicsState()
+	defer pdf.RestoreGraphicsState()
+
+	pdf.ClipPolygon(points)
+
+	if g.Kind == GradientRadial {
+		cx, cy, r := g.circle(n)
+
+		// Rings around a central disc, each a disc with the next one in cut
+		// out, so translucent colours aren't painted over each other.
+		for i := 1; i <= pdfGradientBands; i++ {
+			if err := setPDFFill(pdf, g.at((float64(i)-0.5)/pdfGradientBands)); err != nil {
+				return err
+			}
+
+			var p Path
+			p.Circle(x+cx, y+cy, r*float64(i)/pdfGradientBands)
+
+			if i > 1 {
+				var hole Path
+				hole.Circle(x+cx, y+cy, r*float64(i-1)/pdfGradientBands)
+
+				p.Append(hole.reversed(), 0, 0)
+			}
+
+			pdfPath(pdf, p, 1, 0, 0)
+		}
+
+		return nil
+	}
+
+	x1, y1, x2, y2 := g.line(n)
+	dx, dy := x2-x1, y2-y1
+
+	// Bands are as long as the symbol diagonal either side of the line.
+	px, py := -dy/math.Hypot(dx, dy)*n*1.5, dx/math.Hypot(dx, dy)*n*1.5
+
+	for i := 0; i < pdfGradientBands; i++ {
+		// Bands meet without overlapping, so translucent colours aren't
+		// painted over each other.
+		t0, t1 := float64(i)/pdfGradientBands, float64(i+1)/pdfGradientBands
+
+		// The outer bands extend past the ends of the line.
+		if i == 0 {
+			t0 = -1
+		}
+
+		if i == pdfGradientBands-1 {
+			t1 = 2
+		}
+
+		if err := setPDFFill(pdf, g.at((float64(i)+0.5)/pdfGradientBands)); err != nil {
+			return err
+		}
+
+		ax, ay := x+x1+dx*t0, y+y1+dy*t0
+		bx, by := x+x1+dx*t1, y+y1+dy*t1
+
+		pdf.Polygon([]gopdf.Point{
+			{X: ax - px, Y: ay - py},
+			{X: bx - px, Y: by - py},
+			{X: bx + px, Y: by + py},
+			{X: ax + px, Y: ay + py},
+		}, "F")
+	}
+
+	return nil
+}
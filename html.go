@@ -0,0 +1,143 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// HTMLMode selects the markup of HTML output.
+type HTMLMode int
+
+const (
+	// HTMLTable draws the QR Code as a table, merging runs of modules of the
+	// same colour in a row into a cell spanning several columns. Tables
+	// render in email clients that ignore CSS layout.
+	HTMLTable HTMLMode = iota
+	// HTMLGrid draws the dark modules as rectangles placed on a CSS grid,
+	// which is smaller but needs a client supporting CSS grids.
+	HTMLGrid
+)
+
+// HTMLOptions style HTML output.
+type HTMLOptions struct {
+	Mode HTMLMode
+}
+
+// HTML returns an HTML fragment drawing the QR Code without images, for
+// email clients that block them. It is at most size pixels wide as modules
+// are a whole number of pixels.
+func (q *QRCode) HTML(size int, opts HTMLOptions) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	l := q.rasterSize(size)
+
+	px := l.scale
+	if px == 0 {
+		px = l.size / l.modules
+	}
+
+	if px < 1 {
+		px = 1
+	}
+
+	var html string
+
+	switch opts.Mode {
+	case HTMLGrid:
+		html = q.htmlGrid(px)
+	default:
+		html = q.htmlTable(px)
+	}
+
+	return q.base64([]byte(html), "text/html"), nil
+}
+
+// htmlTable returns the QR Code as a table with px pixels per module.
+func (q *QRCode) htmlTable(px int) string {
+	bitmap := q.symbol.bitmap()
+	n := len(bitmap)
+
+	fg, bg := cssColor(q.moduleColor()), cssColor(q.backgroundColor())
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<table cellpadding="0" cellspacing="0" border="0" style="border-collapse:collapse;table-layout:fixed;width:%dpx;background:%s">`, n*px, bg)
+
+	// Columns are sized up front, as no row need have a cell per column.
+	b.WriteString("<colgroup>")
+
+	for x := 0; x < n; x++ {
+		fmt.Fprintf(&b, `<col style="width:%dpx">`, px)
+	}
+
+	b.WriteString("</colgroup>")
+
+	for _, row := range bitmap {
+		fmt.Fprintf(&b, `<tr style="height:%dpx">`, px)
+
+		for x := 0; x < n; {
+			w := 1
+			for x+w < n && row[x+w] == row[x] {
+				w++
+			}
+
+			b.WriteString("<td")
+
+			if w > 1 {
+				fmt.Fprintf(&b, ` colspan="%d"`, w)
+			}
+
+			fmt.Fprintf(&b, ` style="width:%dpx;height:%dpx;padding:0`, w*px, px)
+
+			// Light cells show the table background.
+			if row[x] {
+				fmt.Fprintf(&b, ";background:%s", fg)
+			}
+
+			b.WriteString(`"></td>`)
+
+			x += w
+		}
+
+		b.WriteString("</tr>")
+	}
+
+	b.WriteString("</table>")
+
+	return b.String()
+}
+
+// htmlGrid returns the QR Code as a CSS grid with px pixels per module.
+func (q *QRCode) htmlGrid(px int) string {
+	n := q.symbol.size
+
+	fg, bg := cssColor(q.moduleColor()), cssColor(q.backgroundColor())
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<div style="display:grid;grid-template-columns:repeat(%d,%dpx);grid-template-rows:repeat(%d,%dpx);width:%dpx;background:%s">`,
+		n, px, n, px, n*px, bg)
+
+	for _, r := range rectangles(q.symbol.bitmap()) {
+		fmt.Fprintf(&b, `<div style="grid-area:%d/%d/span %d/span %d;background:%s"></div>`,
+			r.y+1, r.x+1, r.h, r.w, fg)
+	}
+
+	b.WriteString("</div>")
+
+	return b.String()
+}
+
+// cssColor formats c as a CSS colour.
+func cssColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	if n.A != 0xff {
+		return fmt.Sprintf("rgba(%d,%d,%d,%s)", n.R, n.G, n.B, svgNumber(float64(n.A)/0xff))
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"image/color"
+)
+
+// layer is a set of modules and outlines drawn in the same colour.
+type layer struct {
+	// Modules to draw with shape, in symbol bitmap coordinates.
+	modules [][]bool
+	shape   Shape
+
+	// Outlines drawn in addition to the modules, in module coordinates.
+	paths []Path
+
+	// Fill colour, the module colour if nil.
+	color color.Color
+}
+
+// paint returns the fill colour of the layer.
+func (ly layer) paint(q *QRCode) color.Color {
+	if ly.color == nil {
+		return q.moduleColor()
+	}
+
+	return ly.color
+}
+
+// moduleColor returns the colour of the dark modules.
+func (q *QRCode) moduleColor() color.Color {
+	if q.Inverted {
+		return q.BackgroundColor
+	}
+
+	return q.ForegroundColor
+}
+
+// backgroundColor returns the colour of the light modules and the quiet zone.
+func (q *QRCode) backgroundColor() color.Color {
+	if q.Inverted {
+		return q.ForegroundColor
+	}
+
+	return q.BackgroundColor
+}
+
+// layers splits the symbol by how its modules are drawn. Finder patterns stay
+// square unless styled, whatever the module shape, as scanners rely on their
+// proportions.
+func (q *QRCode) layers() []layer {
+	bitmap := q.symbol.bitmap()
+
+	if q.isPlain() {
+		return []layer{{modules: bitmap, shape: ShapeSquare}}
+	}
+
+	s := q.symbol
+
+	finders, rest := split(bitmap, func(x int, y int) bool {
+		return s.pattern(x, y) == patternFinder
+	})
+
+	eyes, rest := split(rest, func(x int, y int) bool {
+		return s.pattern(x, y) == patternFinderEye
+	})
+
+	var layers []layer
+
+	// Corners of each finder pattern facing the centre of the symbol.
+	facing := []corner{cornerBottomRight, cornerBottomLeft, cornerTopRight}
+
+	if q.FinderStyle.Shape == PatternSquare {
+		layers = append(layers, layer{modules: finders, shape: ShapeSquare, color: q.FinderStyle.Color})
+	} else {
+		ly := layer{color: q.FinderStyle.Color}
+
+		for i, f := range s.finders {
+			ly.paths = append(ly.paths, q.FinderStyle.Shape.ring(float64(f.x), float64(f.y), float64(finderPatternSize), facing[i]))
+		}
+
+		layers = append(layers, ly)
+	}
+
+	if q.EyeStyle.Shape == PatternSquare {
+		layers = append(layers, layer{modules: eyes, shape: ShapeSquare, color: q.EyeStyle.Color})
+	} else {
+		ly := layer{color: q.EyeStyle.Color}
+
+		for i, f := range s.finders {
+			ly.paths = append(ly.paths, q.EyeStyle.Shape.outline(float64(f.x+2), float64(f.y+2), float64(finderPatternSize-4), facing[i]))
+		}
+
+		layers = append(layers, ly)
+	}
+
+	// Alignment patterns are drawn like data modules unless styled.
+	if !q.AlignmentStyle.isDefault() {
+		var alignments [][]bool
+
+		alignments, rest = split(rest, func(x int, y int) bool {
+			return s.pattern(x, y) == patternAlignment
+		})
+
+		if q.AlignmentStyle.Shape == PatternSquare {
+			layers = append(layers, layer{modules: alignments, shape: ShapeSquare, color: q.AlignmentStyle.Color})
+		} else {
+			ly := layer{color: q.AlignmentStyle.Color}
+			shape := q.AlignmentStyle.Shape
+			size := float64(len(alignmentPattern))
+
+			for _, a := range s.alignments {
+				x, y := float64(a.x), float64(a.y)
+
+				ly.paths = append(ly.paths, shape.ring(x, y, size, cornerBottomRight))
+				ly.paths = append(ly.paths, shape.outline(x+2, y+2, 1, cornerBottomRight))
+			}
+
+			layers = append(layers, ly)
+		}
+	}
+
+	shape := q.Shape
+	if shape == nil {
+		shape = ShapeSquare
+	}
+
+	return append(layers, layer{modules: rest, shape: shape})
+}
+
+// isPlain reports whether every module is drawn as a square in the
+// foreground colour.
+func (q *QRCode) isPlain() bool {
+	return isSquare(q.Shape) && q.FinderStyle.isDefault() && q.EyeStyle.isDefault() && q.AlignmentStyle.isDefault()
+}
+
+// split divides the set modules of bitmap into those for which in returns
+// true and the rest.
+func split(bitmap [][]bool, in func(x int, y int) bool) ([][]bool, [][]bool) {
+	a := make([][]bool, len(bitmap))
+	b := make([][]bool, len(bitmap))
+
+	for y := range bitmap {
+		a[y] = make([]bool, len(bitmap[y]))
+		b[y] = make([]bool, len(bitmap[y]))
+
+		for x, v := range bitmap[y] {
+			if in(x, y) {
+				a[y][x] = v
+			} else {
+				b[y][x] = v
+			}
+		}
+	}
+
+	return a, b
+}
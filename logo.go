@@ -0,0 +1,322 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// ErrLogoTooLarge is returned when the logo covers more codewords than the
+// error correction of the QR Code can recover.
+var ErrLogoTooLarge = errors.New("logo too large for the error recovery level")
+
+// Modules kept clear of a centred logo along each edge of the symbol: the
+// finder pattern, its separator and the format information, none of which
+// error correction protects.
+const logoReservedModules = 9
+
+// MaxLogoSize returns the width of the largest logo the QR Code can carry with
+// the current LogoPadding, as a fraction of the symbol width (quiet zone
+// excluded).
+func (q *QRCode) MaxLogoSize() (float64, error) {
+	if err := q.encodeData(); err != nil {
+		return 0, err
+	}
+
+	// Codeword positions are the same whatever the mask.
+	s, err := buildRegularSymbol(q.version, 0, q.encoded, q.Margin)
+	if err != nil {
+		return 0, err
+	}
+
+	side := q.maxLogoArea(s) - 2*q.LogoPadding
+	if side < 0 {
+		side = 0
+	}
+
+	return float64(side) / float64(s.symbolSize), nil
+}
+
+// logoArea returns the modules to clear for the logo, padding included, in
+// bitmap coordinates.
+func (q *QRCode) logoArea(s *symbol) (moduleRect, error) {
+	maxSide := q.maxLogoArea(s)
+
+	var side int
+
+	if q.LogoSize > 0 {
+		side = int(math.Ceil(q.LogoSize*float64(s.symbolSize))) + 2*q.LogoPadding
+	} else {
+		side = maxSide
+	}
+
+	// Keep the area centred on the symbol, which has an odd size.
+	if side%2 == 0 {
+		side++
+	}
+
+	if side > maxSide || side-2*q.LogoPadding < 1 {
+		return moduleRect{}, ErrLogoTooLarge
+	}
+
+	return s.centred(side), nil
+}
+
+// maxLogoArea returns the side of the largest centred area whose codewords the
+// error correction can recover.
+func (q *QRCode) maxLogoArea(s *symbol) int {
+	best := 0
+
+	for side := 1; side <= s.symbolSize-2*logoReservedModules; side += 2 {
+		if !q.recoverable(q.damage(s, s.centred(side), false)) {
+			break
+		}
+
+		best = side
+	}
+
+	return best
+}
+
+// centred returns a side x side area at the centre of the symbol.
+func (s *symbol) centred(side int) moduleRect {
+	o := s.quietZoneSize + (s.symbolSize-side)/2
+
+	return moduleRect{x: o, y: o, w: side, h: side}
+}
+
+// damage counts the codewords of each block with a module in r. If darkOnly
+// is set only dark modules count, as clearing light modules changes nothing.
+func (q *QRCode) damage(s *symbol, r moduleRect, darkOnly bool) []int {
+	blocks := q.version.codewordBlocks()
+	result := make([]int, q.version.numBlocks())
+	seen := make([]bool, len(blocks))
+
+	for y := r.y; y < r.y+r.h; y++ {
+		for x := r.x; x < r.x+r.w; x++ {
+			c := s.codewords[y][x]
+
+			if c < 0 || seen[c] || darkOnly && !s.module[y][x] {
+				continue
+			}
+
+			seen[c] = true
+			result[blocks[c]]++
+		}
+	}
+
+	return result
+}
+
+// recoverable reports whether every block can correct its damaged codewords.
+func (q *QRCode) recoverable(damage []int) bool {
+	i := 0
+
+	for _, b := range q.version.block {
+		// Codewords kept aside against misdecoding can't correct errors.
+		capacity := (b.numCodewords - b.numDataCodewords - q.version.numMisdecodeProtection()) / 2
+
+		for j := 0; j < b.numBlocks; j++ {
+			if damage[i] > capacity {
+				return false
+			}
+
+			i++
+		}
+	}
+
+	return true
+}
+
+// logoDamage returns the number of dark modules cleared under the logo,
+// counted in codewords, used to pick the least affected mask.
+func (q *QRCode) logoDamage(s *symbol, r moduleRect) int {
+	total := 0
+
+	for _, d := range q.damage(s, r, true) {
+		total += d
+	}
+
+	return total
+}
+
+// clear sets the modules in r to light, keeping alignment patterns, which
+// are redrawn over the logo.
+func (s *symbol) clear(r moduleRect) {
+	for y := r.y; y < r.y+r.h; y++ {
+		for x := r.x; x < r.x+r.w; x++ {
+			if s.patterns[y][x] != patternAlignment {
+				s.module[y][x] = false
+			}
+		}
+	}
+}
+
+// logoOverlay returns the alignment patterns the logo covers, in module
+// coordinates. Error correction doesn't protect them, so they are drawn over
+// the logo on a light square.
+func (q *QRCode) logoOverlay() []pathFill {
+	s := q.symbol
+	n := len(alignmentPattern)
+
+	light := q.backgroundColor()
+	if isTransparent(light) {
+		light = q.matte()
+	}
+
+	// Alignment patterns are drawn like data modules unless styled.
+	shape := q.Shape
+	if shape == nil || !q.AlignmentStyle.isDefault() {
+		shape = ShapeSquare
+	}
+
+	var fills []pathFill
+
+	for _, a := range s.alignments {
+		r := q.logoRect
+		if a.x >= r.x+r.w || a.x+n <= r.x || a.y >= r.y+r.h || a.y+n <= r.y {
+			continue
+		}
+
+		x, y := float64(a.x), float64(a.y)
+
+		var background Path
+		background.Rect(x, y, float64(n), float64(n))
+
+		var pattern Path
+
+		if q.AlignmentStyle.Shape == PatternSquare {
+			bitmap := make([][]bool, s.size)
+			for i := range bitmap {
+				bitmap[i] = make([]bool, s.size)
+			}
+
+			for j, row := range alignmentPattern {
+				copy(bitmap[a.y+j][a.x:], row)
+			}
+
+			for _, p := range modulePaths(bitmap, shape) {
+				pattern.Append(p, 0, 0)
+			}
+		} else {
+			pattern = q.AlignmentStyle.Shape.ring(x, y, float64(n), cornerBottomRight)
+			pattern.Append(q.AlignmentStyle.Shape.outline(x+2, y+2, 1, cornerBottomRight), 0, 0)
+		}
+
+		dark := q.AlignmentStyle.Color
+		if dark == nil {
+			dark = q.moduleColor()
+
+			// Gradients are sampled at the centre of the pattern.
+			if g := q.gradient(layer{}); g != nil {
+				o := float64(s.quietZoneSize)
+				dark = g.at(g.offset(x+float64(n)/2-o, y+float64(n)/2-o, float64(s.symbolSize)))
+			}
+		}
+
+		fills = append(fills, pathFill{background, light}, pathFill{pattern, dark})
+	}
+
+	return fills
+}
+
+// logoBox returns where the logo is drawn, preserving its aspect ratio, with
+// the logo area scaled by s and offset by (dx, dy).
+func (q *QRCode) logoBox(s float64, dx float64, dy float64) (x float64, y float64, w float64, h float64) {
+	r := q.logoRect
+	b := q.Logo.Bounds()
+
+	w, h = float64(r.w)*s, float64(r.h)*s
+
+	if b.Dx() > b.Dy() {
+		h = w * float64(b.Dy()) / float64(b.Dx())
+	} else if b.Dy() > b.Dx() {
+		w = h * float64(b.Dx()) / float64(b.Dy())
+	}
+
+	x = float64(r.x)*s + dx + (float64(r.w)*s-w)/2
+	y = float64(r.y)*s + dy + (float64(r.h)*s-h)/2
+
+	return x, y, w, h
+}
+
+// drawLogo composites the logo over img using bilinear sampling.
+func (q *QRCode) drawLogo(img draw.Image, l rasterLayout) {
+	x, y, w, h := q.logoBox(l.moduleSize(), float64(l.offset), float64(l.offset))
+	src := q.Logo
+	b := src.Bounds()
+
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	r = r.Intersect(img.Bounds())
+
+	// Samples are mixed premultiplied so transparent pixels don't bleed their
+	// colour into the edges of the logo.
+	sample := func(sx int, sy int) color.RGBA64 {
+		if sx >= b.Max.X {
+			sx = b.Max.X - 1
+		}
+
+		if sy >= b.Max.Y {
+			sy = b.Max.Y - 1
+		}
+
+		return color.RGBA64Model.Convert(src.At(sx, sy)).(color.RGBA64)
+	}
+
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		fy := (float64(py)+0.5-y)/h*float64(b.Dy()) - 0.5
+		if fy < 0 {
+			fy = 0
+		}
+
+		sy := int(fy)
+		ty := fy - float64(sy)
+
+		for px := r.Min.X; px < r.Max.X; px++ {
+			fx := (float64(px)+0.5-x)/w*float64(b.Dx()) - 0.5
+			if fx < 0 {
+				fx = 0
+			}
+
+			sx := int(fx)
+			tx := fx - float64(sx)
+
+			c00 := sample(b.Min.X+sx, b.Min.Y+sy)
+			c10 := sample(b.Min.X+sx+1, b.Min.Y+sy)
+			c01 := sample(b.Min.X+sx, b.Min.Y+sy+1)
+			c11 := sample(b.Min.X+sx+1, b.Min.Y+sy+1)
+
+			mix := func(a uint16, b uint16, c uint16, d uint16) uint16 {
+				top := float64(a)*(1-tx) + float64(b)*tx
+				bottom := float64(c)*(1-tx) + float64(d)*tx
+
+				return uint16(top*(1-ty) + bottom*ty)
+			}
+
+			c := color.RGBA64{
+				R: mix(c00.R, c10.R, c01.R, c11.R),
+				G: mix(c00.G, c10.G, c01.G, c11.G),
+				B: mix(c00.B, c10.B, c01.B, c11.B),
+				A: mix(c00.A, c10.A, c01.A, c11.A),
+			}
+
+			draw.Draw(img, image.Rect(px, py, px+1, py+1), image.NewUniform(c), image.Point{}, draw.Over)
+		}
+	}
+}
+
+// logoDataURI returns the logo as a PNG data URI.
+func (q *QRCode) logoDataURI() (string, error) {
+	var b bytes.Buffer
+
+	if err := png.Encode(&b, q.Logo); err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
@@ -0,0 +1,68 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestLogoKeepsAlignmentPatterns(t *testing.T) {
+	// An opaque logo in a colour no module has.
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < len(logo.Pix); i += 4 {
+		logo.Pix[i], logo.Pix[i+3] = 0xff, 0xff
+	}
+
+	for _, length := range []int{100, 500, 1000, 2000} {
+		q, err := New(strings.Repeat("a", length), Low)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		q.Logo = logo
+		q.Scale = ScaleModule
+
+		img, err := q.Image(4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s := q.symbol
+		r := q.logoRect
+		covered := 0
+
+		for _, a := range s.alignments {
+			for j, row := range alignmentPattern {
+				for i, dark := range row {
+					if s.module[a.y+j][a.x+i] != dark {
+						t.Fatalf("version %d: alignment module (%d, %d) cleared", q.versionNumber, a.x+i, a.y+j)
+					}
+				}
+			}
+
+			if a.x >= r.x+r.w || a.x+5 <= r.x || a.y >= r.y+r.h || a.y+5 <= r.y {
+				continue
+			}
+
+			covered++
+
+			// The centre module is dark and the ring around it light, over
+			// the logo.
+			centre := img.At((a.x+2)*4+2, (a.y+2)*4+2)
+			ring := img.At((a.x+1)*4+2, (a.y+2)*4+2)
+
+			if c := color.GrayModel.Convert(centre).(color.Gray); c.Y != 0 {
+				t.Errorf("version %d: alignment centre drawn as %v", q.versionNumber, centre)
+			}
+
+			if c := color.GrayModel.Convert(ring).(color.Gray); c.Y != 0xff {
+				t.Errorf("version %d: alignment ring drawn as %v", q.versionNumber, ring)
+			}
+		}
+
+		if q.versionNumber >= 7 && covered == 0 {
+			t.Errorf("version %d: logo covers no alignment pattern", q.versionNumber)
+		}
+	}
+}
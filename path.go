@@ -0,0 +1,357 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Path is an outline made of straight lines and cubic Bézier curves. Paths
+// returned by a Shape use module coordinates: (0, 0) is the top left corner
+// of the module and (1, 1) its bottom right corner. Subpaths are filled with
+// the nonzero winding rule.
+type Path struct {
+	ops []pathOp
+}
+
+// pathFill is a path filled in a single colour.
+type pathFill struct {
+	path  Path
+	color color.Color
+}
+
+type pathOp struct {
+	// One of 'M', 'L', 'C' or 'Z', as in SVG path data.
+	op  byte
+	pts [3]vec
+}
+
+type vec struct {
+	x, y float64
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x float64, y float64) {
+	p.ops = append(p.ops, pathOp{op: 'M', pts: [3]vec{{x, y}}})
+}
+
+// LineTo adds a straight line to (x, y).
+func (p *Path) LineTo(x float64, y float64) {
+	p.ops = append(p.ops, pathOp{op: 'L', pts: [3]vec{{x, y}}})
+}
+
+// CubeTo adds a cubic Bézier curve to (x, y) with control points (x1, y1)
+// and (x2, y2).
+func (p *Path) CubeTo(x1 float64, y1 float64, x2 float64, y2 float64, x float64, y float64) {
+	p.ops = append(p.ops, pathOp{op: 'C', pts: [3]vec{{x1, y1}, {x2, y2}, {x, y}}})
+}
+
+// Close closes the current subpath.
+func (p *Path) Close() {
+	p.ops = append(p.ops, pathOp{op: 'Z'})
+}
+
+// Rect adds a rectangle subpath, drawn clockwise.
+func (p *Path) Rect(x float64, y float64, w float64, h float64) {
+	p.MoveTo(x, y)
+	p.LineTo(x+w, y)
+	p.LineTo(x+w, y+h)
+	p.LineTo(x, y+h)
+	p.Close()
+}
+
+// RoundedRect adds a clockwise rectangle subpath with the corners rounded to
+// the given radii, starting at the top left corner.
+func (p *Path) RoundedRect(x float64, y float64, w float64, h float64, tl float64, tr float64, br float64, bl float64) {
+	// Control point distance approximating a quarter circle.
+	const k = 0.5523
+
+	p.MoveTo(x, y+tl)
+
+	if tl > 0 {
+		p.CubeTo(x, y+tl*(1-k), x+tl*(1-k), y, x+tl, y)
+	}
+
+	p.LineTo(x+w-tr, y)
+
+	if tr > 0 {
+		p.CubeTo(x+w-tr*(1-k), y, x+w, y+tr*(1-k), x+w, y+tr)
+	}
+
+	p.LineTo(x+w, y+h-br)
+
+	if br > 0 {
+		p.CubeTo(x+w, y+h-br*(1-k), x+w-br*(1-k), y+h, x+w-br, y+h)
+	}
+
+	p.LineTo(x+bl, y+h)
+
+	if bl > 0 {
+		p.CubeTo(x+bl*(1-k), y+h, x, y+h-bl*(1-k), x, y+h-bl)
+	}
+
+	p.Close()
+}
+
+// Circle adds a clockwise circle subpath.
+func (p *Path) Circle(cx float64, cy float64, r float64) {
+	p.RoundedRect(cx-r, cy-r, 2*r, 2*r, r, r, r, r)
+}
+
+// Append adds the subpaths of o, offset by (dx, dy).
+func (p *Path) Append(o Path, dx float64, dy float64) {
+	for _, op := range o.ops {
+		for i := range op.pts {
+			op.pts[i].x += dx
+			op.pts[i].y += dy
+		}
+
+		p.ops = append(p.ops, op)
+	}
+}
+
+// Number of straight lines approximating a cubic curve.
+const curveSegments = 16
+
+// polygons flattens the path into closed polygons, scaled by s and offset by
+// (dx, dy).
+func (p Path) polygons(s float64, dx float64, dy float64) [][]vec {
+	var result [][]vec
+
+	var current []vec
+
+	at := func(v vec) vec {
+		return vec{x: v.x*s + dx, y: v.y*s + dy}
+	}
+
+	flush := func() {
+		if len(current) > 2 {
+			result = append(result, current)
+		}
+
+		current = nil
+	}
+
+	for _, op := range p.ops {
+		switch op.op {
+		case 'M':
+			flush()
+			current = append(current, at(op.pts[0]))
+		case 'L':
+			current = append(current, at(op.pts[0]))
+		case 'C':
+			if len(current) == 0 {
+				continue
+			}
+
+			p0 := current[len(current)-1]
+			p1, p2, p3 := at(op.pts[0]), at(op.pts[1]), at(op.pts[2])
+
+			for i := 1; i <= curveSegments; i++ {
+				t := float64(i) / curveSegments
+				u := 1 - t
+
+				current = append(current, vec{
+					x: u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+					y: u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+				})
+			}
+		case 'Z':
+			flush()
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+// svgData returns SVG path data for the path offset by (dx, dy).
+func (p Path) svgData(dx float64, dy float64) string {
+	var d strings.Builder
+
+	for _, op := range p.ops {
+		d.WriteByte(op.op)
+
+		n := 1
+		switch op.op {
+		case 'C':
+			n = 3
+		case 'Z':
+			n = 0
+		}
+
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				d.WriteByte(' ')
+			}
+
+			fmt.Fprintf(&d, "%s %s", svgNumber(op.pts[i].x+dx), svgNumber(op.pts[i].y+dy))
+		}
+	}
+
+	return d.String()
+}
+
+// svgNumber formats v with at most three decimals and no trailing zeros.
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// scanner finds the runs of pixels inside polygons a row at a time, so that
+// an image can be filled in bands without sorting its edges for each.
+type scanner struct {
+	edges     []scanEdge
+	active    []scanEdge
+	crossings []scanCrossing
+	next      int
+}
+
+type scanEdge struct {
+	x0, y0, x1, y1 float64
+	dir            int
+}
+
+type scanCrossing struct {
+	x   float64
+	dir int
+}
+
+func newScanner(polygons [][]vec) *scanner {
+	s := &scanner{}
+
+	for _, poly := range polygons {
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+
+			switch {
+			case a.y < b.y:
+				s.edges = append(s.edges, scanEdge{x0: a.x, y0: a.y, x1: b.x, y1: b.y, dir: 1})
+			case a.y > b.y:
+				s.edges = append(s.edges, scanEdge{x0: b.x, y0: b.y, x1: a.x, y1: a.y, dir: -1})
+			}
+		}
+	}
+
+	sort.Slice(s.edges, func(i int, j int) bool {
+		return s.edges[i].y0 < s.edges[j].y0
+	})
+
+	return s
+}
+
+// scan calls span for every run of pixels in row y, width pixels wide, whose
+// centres fall inside the polygons under the nonzero winding rule. Rows must
+// be scanned from the top down.
+func (s *scanner) scan(y int, width int, span func(x0 int, x1 int)) {
+	yc := float64(y) + 0.5
+
+	for s.next < len(s.edges) && s.edges[s.next].y0 <= yc {
+		s.active = append(s.active, s.edges[s.next])
+		s.next++
+	}
+
+	s.crossings = s.crossings[:0]
+	kept := s.active[:0]
+
+	for _, e := range s.active {
+		if e.y1 <= yc {
+			continue
+		}
+
+		kept = append(kept, e)
+
+		if e.y0 <= yc {
+			x := e.x0 + (yc-e.y0)/(e.y1-e.y0)*(e.x1-e.x0)
+			s.crossings = append(s.crossings, scanCrossing{x: x, dir: e.dir})
+		}
+	}
+
+	s.active = kept
+
+	sort.Slice(s.crossings, func(i int, j int) bool {
+		return s.crossings[i].x < s.crossings[j].x
+	})
+
+	winding := 0
+	start := 0.0
+
+	for _, c := range s.crossings {
+		if winding == 0 {
+			start = c.x
+		}
+
+		winding += c.dir
+		if winding != 0 {
+			continue
+		}
+
+		x0 := int(math.Ceil(start - 0.5))
+		x1 := int(math.Ceil(c.x - 0.5))
+
+		if x0 < 0 {
+			x0 = 0
+		}
+
+		if x1 > width {
+			x1 = width
+		}
+
+		if x0 < x1 {
+			span(x0, x1)
+		}
+	}
+}
+
+// reversed returns the path with every subpath traced in the opposite
+// direction, which makes it a hole when appended to an enclosing path.
+func (p Path) reversed() Path {
+	var r Path
+
+	for start := 0; start < len(p.ops); {
+		end := start + 1
+		for end < len(p.ops) && p.ops[end].op != 'M' {
+			end++
+		}
+
+		sub := p.ops[start:end]
+		start = end
+
+		closed := sub[len(sub)-1].op == 'Z'
+		if closed {
+			sub = sub[:len(sub)-1]
+		}
+
+		last := sub[len(sub)-1].end()
+		r.MoveTo(last.x, last.y)
+
+		for i := len(sub) - 1; i > 0; i-- {
+			prev := sub[i-1].end()
+
+			if sub[i].op == 'C' {
+				c := sub[i].pts
+				r.CubeTo(c[1].x, c[1].y, c[0].x, c[0].y, prev.x, prev.y)
+			} else {
+				r.LineTo(prev.x, prev.y)
+			}
+		}
+
+		if closed {
+			r.Close()
+		}
+	}
+
+	return r
+}
+
+// end returns the point a segment finishes at.
+func (op pathOp) end() vec {
+	if op.op == 'C' {
+		return op.pts[2]
+	}
+
+	return op.pts[0]
+}
@@ -0,0 +1,97 @@
+package qrcode
+
+import (
+	"image/color"
+)
+
+// PatternShape is the outline of a finder or alignment pattern part.
+type PatternShape int
+
+const (
+	// PatternSquare draws the pattern as plain modules. It is the default.
+	PatternSquare PatternShape = iota
+	// PatternRounded rounds the corners of the pattern.
+	PatternRounded
+	// PatternCircle draws concentric circles.
+	PatternCircle
+	// PatternLeaf rounds two opposite corners, keeping the corner that faces
+	// the centre of the symbol and the one opposite to it square.
+	PatternLeaf
+)
+
+// PatternStyle styles a part of the finder or alignment patterns.
+type PatternStyle struct {
+	Shape PatternShape
+
+	// Colour of the part, that of the other dark modules if nil.
+	Color color.Color
+}
+
+func (s PatternStyle) isDefault() bool {
+	return s.Shape == PatternSquare && s.Color == nil
+}
+
+type corner int
+
+const (
+	cornerTopLeft corner = iota
+	cornerTopRight
+	cornerBottomRight
+	cornerBottomLeft
+)
+
+// radii returns the corner radii of a size x size pattern part, ordered top
+// left, top right, bottom right, bottom left. facing is the corner pointing
+// towards the centre of the symbol.
+func (s PatternShape) radii(size float64, facing corner) [4]float64 {
+	var r [4]float64
+
+	for i := range r {
+		switch s {
+		case PatternRounded:
+			r[i] = size * 0.3
+		case PatternCircle:
+			r[i] = size / 2
+		case PatternLeaf:
+			if corner(i) != facing && corner(i) != (facing+2)%4 {
+				r[i] = size / 2
+			}
+		}
+	}
+
+	return r
+}
+
+// outline returns the outline of a size x size pattern part with its top left
+// corner at (x, y).
+func (s PatternShape) outline(x float64, y float64, size float64, facing corner) Path {
+	r := s.radii(size, facing)
+
+	var p Path
+	p.RoundedRect(x, y, size, size, r[0], r[1], r[2], r[3])
+
+	return p
+}
+
+// ring returns the outline of a size x size pattern ring one module thick,
+// with its top left corner at (x, y).
+func (s PatternShape) ring(x float64, y float64, size float64, facing corner) Path {
+	p := s.outline(x, y, size, facing)
+
+	// The hole follows the outer edge one module in.
+	r := s.radii(size, facing)
+
+	for i := range r {
+		r[i]--
+		if r[i] < 0 {
+			r[i] = 0
+		}
+	}
+
+	var hole Path
+	hole.RoundedRect(x+1, y+1, size-2, size-2, r[0], r[1], r[2], r[3])
+
+	p.Append(hole.reversed(), 0, 0)
+
+	return p
+}
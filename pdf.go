@@ -0,0 +1,307 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+
+	"github.com/signintech/gopdf"
+)
+
+// PDF returns a single page PDF document with the QR Code drawn as vector
+// paths. The page has the physical size of the equivalent raster image,
+// frame included.
+func (q *QRCode) PDF(size int) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	l := q.rasterSize(size)
+
+	var b bytes.Buffer
+
+	pdf := gopdf.GoPdf{}
+
+	// Page size in points, matching the physical size of the image.
+	width := q.physicalWidth(l.size)
+
+	// Points per module of the frame.
+	s := float64(l.size) / l.moduleSize()
+	k := width / s
+
+	fl, err := q.frame(s)
+	if err != nil {
+		return nil, err
+	}
+
+	rect := gopdf.Rect{W: width + 2*fl.left*k, H: width + (fl.top+fl.bottom)*k}
+
+	pdf.Start(gopdf.Config{Unit: gopdf.UnitPT, PageSize: rect})
+	pdf.AddPage()
+
+	x, y := fl.left*k, fl.top*k
+
+	if err := pdfFills(&pdf, fl.fills, k, x, y); err != nil {
+		return nil, err
+	}
+
+	if err := q.drawPDF(&pdf, x, y, width, l); err != nil {
+		return nil, err
+	}
+
+	if err := pdf.Write(&b); err != nil {
+		return nil, err
+	}
+
+	bts := b.Bytes()
+
+	if q.Base64 {
+		bts = []byte(fmt.Sprintf("data:application/pdf;base64,%s", base64.StdEncoding.EncodeToString(bts)))
+	}
+
+	return bts, nil
+}
+
+// DrawPDF draws the QR Code as vector paths on the current page of pdf. The
+// top left corner is placed at (x, y) and width includes the quiet zone and
+// frame, both in the units pdf was started with.
+func (q *QRCode) DrawPDF(pdf *gopdf.GoPdf, x float64, y float64, width float64) error {
+	if err := q.encode(); err != nil {
+		return err
+	}
+
+	n := q.symbol.size
+	l := rasterLayout{size: n, scale: 1, modules: n}
+
+	fl, err := q.frame(float64(n))
+	if err != nil {
+		return err
+	}
+
+	// Units per module.
+	k := width / (float64(n) + 2*fl.left)
+	x, y = x+fl.left*k, y+fl.top*k
+
+	if err := pdfFills(pdf, fl.fills, k, x, y); err != nil {
+		return err
+	}
+
+	return q.drawPDF(pdf, x, y, k*float64(n), l)
+}
+
+// pdfHeight returns the height DrawPDF draws the QR Code at width, which
+// exceeds width when captioned.
+func (q *QRCode) pdfHeight(width float64) (float64, error) {
+	if err := q.encode(); err != nil {
+		return 0, err
+	}
+
+	n := float64(q.symbol.size)
+
+	fl, err := q.frame(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return width * (n + fl.top + fl.bottom) / (n + 2*fl.left), nil
+}
+
+// pdfFills draws fills, scaled by s and offset by (x, y).
+func pdfFills(pdf *gopdf.GoPdf, fills []pathFill, s float64, x float64, y float64) error {
+	defer pdf.ClearTransparency()
+
+	for _, p := range fills {
+		if err := setPDFFill(pdf, p.color); err != nil {
+			return err
+		}
+
+		pdfPath(pdf, p.path, s, x, y)
+	}
+
+	return nil
+}
+
+func (q *QRCode) drawPDF(pdf *gopdf.GoPdf, x float64, y float64, width float64, l rasterLayout) error {
+	// Units per pixel of the layout, and per module.
+	k := width / float64(l.size)
+	moduleSize := k * float64(l.scale)
+
+	if l.scale == 0 {
+		moduleSize = width / float64(l.modules)
+	}
+
+	pdf.SaveGraphicsState()
+	defer pdf.RestoreGraphicsState()
+	defer pdf.ClearTransparency()
+
+	// A transparent background leaves the page showing through.
+	if !isTransparent(q.backgroundColor()) {
+		if err := setPDFFill(pdf, q.backgroundColor()); err != nil {
+			return err
+		}
+
+		if err := pdfRect(pdf, x, y, width, width); err != nil {
+			return err
+		}
+	}
+
+	// Top left corner of the first module.
+	x0 := x + k*float64(l.offset)
+	y0 := y + k*float64(l.offset)
+
+	for _, ly := range q.layers() {
+		if g := q.gradient(ly); g != nil {
+			// Symbol area, quiet zone excluded.
+			o := float64(q.symbol.quietZoneSize) * moduleSize
+			n := float64(q.symbol.symbolSize) * moduleSize
+
+			points := pdfPoints(layerPolygons(ly, moduleSize, x0, y0))
+
+			if err := g.pdfGradient(pdf, points, x0+o, y0+o, n); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := setPDFFill(pdf, ly.paint(q)); err != nil {
+			return err
+		}
+
+		for _, p := range ly.paths {
+			pdfPath(pdf, p, moduleSize, x0, y0)
+		}
+
+		if ly.modules == nil {
+			continue
+		}
+
+		if !isSquare(ly.shape) {
+			for _, p := range modulePaths(ly.modules, ly.shape) {
+				pdfPath(pdf, p, moduleSize, x0, y0)
+			}
+
+			continue
+		}
+
+		for _, r := range rectangles(ly.modules) {
+			err := pdfRect(pdf,
+				x0+float64(r.x)*moduleSize, y0+float64(r.y)*moduleSize,
+				float64(r.w)*moduleSize, float64(r.h)*moduleSize,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if q.Logo != nil {
+		lx, ly, lw, lh := q.logoBox(moduleSize, x0, y0)
+
+		if err := pdf.ImageFrom(q.Logo, lx, ly, &gopdf.Rect{W: lw, H: lh}); err != nil {
+			return err
+		}
+
+		if err := pdfFills(pdf, q.logoOverlay(), moduleSize, x0, y0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// layerPolygons returns the outline of everything ly draws, scaled by s and
+// offset by (x, y).
+func layerPolygons(ly layer, s float64, x float64, y float64) [][]vec {
+	var polygons [][]vec
+
+	paths := append([]Path(nil), ly.paths...)
+
+	if ly.modules != nil && !isSquare(ly.shape) {
+		paths = append(paths, modulePaths(ly.modules, ly.shape)...)
+	} else if ly.modules != nil {
+		for _, r := range rectangles(ly.modules) {
+			var p Path
+			p.Rect(float64(r.x), float64(r.y), float64(r.w), float64(r.h))
+
+			paths = append(paths, p)
+		}
+	}
+
+	for _, p := range paths {
+		polygons = append(polygons, p.polygons(s, x, y)...)
+	}
+
+	return polygons
+}
+
+func pdfRect(pdf *gopdf.GoPdf, x float64, y float64, w float64, h float64) error {
+	return pdf.RectFromUpperLeftWithOpts(gopdf.DrawableRectOptions{
+		X:          x,
+		Y:          y,
+		Rect:       gopdf.Rect{W: w, H: h},
+		PaintStyle: gopdf.FillPaintStyle,
+	})
+}
+
+// pdfPath fills p, scaled by s and offset by (x, y). Subpaths are joined into
+// a single polygon through their first points; the joins trace back over
+// themselves, so they add no area and holes are kept.
+func pdfPath(pdf *gopdf.GoPdf, p Path, s float64, x float64, y float64) {
+	if points := pdfPoints(p.polygons(s, x, y)); len(points) > 0 {
+		pdf.Polygon(points, "F")
+	}
+}
+
+// pdfPoints joins polygons into a single one through the first point of the
+// first polygon. The joins are traversed both ways, so they cancel out under
+// the nonzero rule.
+func pdfPoints(polygons [][]vec) []gopdf.Point {
+	var points []gopdf.Point
+
+	for _, poly := range polygons {
+		for _, v := range poly {
+			points = append(points, gopdf.Point{X: v.x, Y: v.y})
+		}
+
+		points = append(points, gopdf.Point{X: poly[0].x, Y: poly[0].y})
+
+		if len(polygons) > 1 {
+			points = append(points, gopdf.Point{X: polygons[0][0].x, Y: polygons[0][0].y})
+		}
+	}
+
+	return points
+}
+
+// setPDFFill sets the fill colour and opacity of pdf. CMYK colours are kept
+// in the CMYK colour space for print output.
+func setPDFFill(pdf *gopdf.GoPdf, c color.Color) error {
+	switch c := c.(type) {
+	case color.CMYK:
+		pdf.SetFillColorCMYK(percent(c.C), percent(c.M), percent(c.Y), percent(c.K))
+		pdf.ClearTransparency()
+
+		return nil
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	pdf.SetFillColor(n.R, n.G, n.B)
+
+	if n.A == 0xff {
+		pdf.ClearTransparency()
+
+		return nil
+	}
+
+	return pdf.SetTransparency(gopdf.Transparency{
+		Alpha:         float64(n.A) / 0xff,
+		BlendModeType: gopdf.NormalBlendMode,
+	})
+}
+
+func percent(v uint8) uint8 {
+	return uint8((int(v)*100 + 0x7f) / 0xff)
+}
@@ -7,13 +7,9 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/jpeg"
 	"image/png"
-	"math"
-
-	"github.com/signintech/gopdf"
-
-	svgo "github.com/ajstarks/svgo"
 
 	"github.com/RashadAnsari/go-qrcode/internal/bitset"
 	"github.com/RashadAnsari/go-qrcode/internal/reedsolomon"
@@ -31,20 +27,113 @@ type QRCode struct {
 	ForegroundColor color.Color
 	BackgroundColor color.Color
 
+	// Colour JPEG and BMP output is flattened onto, as those formats have no
+	// transparency, and GIF output when it needs more than a palette. White
+	// if nil.
+	Matte color.Color
+
+	// Inverted renders a reflectance reversed symbol: dark modules are drawn
+	// in BackgroundColor, and light modules and the quiet zone around them in
+	// ForegroundColor. Gradients and pattern colours still fill the modules.
+	Inverted bool
+
+	// Gradient filling the foreground instead of ForegroundColor, if set.
+	// Pattern parts styled with their own colour keep it.
+	ForegroundGradient *Gradient
+
 	// Qr Code margin.
 	Margin int
 
 	// Base 64 output.
 	Base64 bool
 
+	// How raster renderers map modules to pixels.
+	Scale ScaleMode
+
+	// Whether raster renderers smooth the edges of modules.
+	Antialias AntialiasMode
+
+	// SVG output style.
+	SVGMode SVGMode
+
+	// Border, padding and captions around the QR Code in raster, SVG and PDF
+	// output.
+	Frame Frame
+
+	// Shape of the dark modules, square if nil.
+	Shape Shape
+
+	// Styles of the finder pattern rings, the eyes at their centre and the
+	// alignment patterns.
+	FinderStyle    PatternStyle
+	EyeStyle       PatternStyle
+	AlignmentStyle PatternStyle
+
+	// Physical output size, overriding the size argument of the renderers.
+	// Width covers the whole image including the quiet zone. ModuleSize sets
+	// the size of a single module and takes precedence over Width.
+	Width      Length
+	ModuleSize Length
+
+	// Output resolution in dots per inch. Written to PNG and JPEG metadata
+	// when set, and assumed to be 72 otherwise.
+	DPI float64
+
+	// Image drawn at the centre of the symbol over cleared modules. LogoSize
+	// is its width as a fraction of the symbol width, the largest the error
+	// correction allows if 0. LogoPadding is the number of cleared modules
+	// around it.
+	Logo        image.Image
+	LogoSize    float64
+	LogoPadding int
+
+	// Strict makes the renderers fail with the first problem reported by
+	// Warnings instead of producing a code that may not scan.
+	Strict bool
+
 	encoder *dataEncoder
 	version qrCodeVersion
 
-	data   *bitset.Bitset
-	symbol *symbol
-	mask   int
+	data    *bitset.Bitset
+	encoded *bitset.Bitset
+	symbol  *symbol
+	mask    int
+
+	// Area the logo is drawn in, in bitmap coordinates.
+	logoRect moduleRect
 }
 
+// ScaleMode controls how raster renderers map modules to pixels.
+type ScaleMode int
+
+const (
+	// ScaleStretch fits the symbol to exactly size pixels. Modules may differ
+	// in size by a pixel when size is not a multiple of the symbol size.
+	ScaleStretch ScaleMode = iota
+	// ScaleModule treats size as the number of pixels per module and derives
+	// the image size from the symbol size. A negative size does the same.
+	ScaleModule
+	// ScaleCenter uses the largest integer number of pixels per module that
+	// fits in size and centres the symbol, spreading leftover pixels into the
+	// quiet zone.
+	ScaleCenter
+)
+
+// AntialiasMode controls edge smoothing in raster renderers.
+type AntialiasMode int
+
+const (
+	// AntialiasAuto smooths curved and diagonal edges of module shapes and
+	// styled patterns, keeping plain square modules crisp.
+	AntialiasAuto AntialiasMode = iota
+	// AntialiasNever keeps every edge crisp, each pixel taking the colour of
+	// the module under its centre.
+	AntialiasNever
+	// AntialiasAlways also smooths square modules, blending the edges of
+	// modules that don't fall on whole pixels when stretched.
+	AntialiasAlways
+)
+
 func New(content string, level RecoveryLevel) (*QRCode, error) {
 	encoders := []dataEncoderType{dataEncoderType1To9, dataEncoderType10To26, dataEncoderType27To40}
 
@@ -98,53 +187,83 @@ func New(content string, level RecoveryLevel) (*QRCode, error) {
 	return q, nil
 }
 
-func (q *QRCode) image(size int) (image.Image, error) {
-	// Build QR code.
+// Image returns the QR Code as an image of size x size pixels, extended by
+// the Frame if set.
+func (q *QRCode) Image(size int) (image.Image, error) {
+	return q.image(size)
+}
+
+// Draw renders the QR Code centred in r of dst, blending it over the existing
+// pixels so translucent colours keep whatever is underneath.
+func (q *QRCode) Draw(dst draw.Image, r image.Rectangle) error {
 	if err := q.encode(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// Minimum pixels (both width and height) required.
-	realSize := q.symbol.size
+	// The frame is laid out around the symbol, so it is shrunk to leave room.
+	n := float64(q.symbol.size)
 
-	// Variable size support.
-	if size < 0 {
-		size = size * -1 * realSize
+	fl, err := q.frame(n)
+	if err != nil {
+		return err
 	}
 
-	// Actual pixels available to draw the symbol. Automatically increase the
-	// image size if it's not large enough.
-	if size < realSize {
-		size = realSize
+	size := int(float64(r.Dx()) * n / (n + 2*fl.left))
+	if h := int(float64(r.Dy()) * n / (n + fl.top + fl.bottom)); h < size {
+		size = h
 	}
 
-	// Output image.
-	rect := image.Rectangle{Min: image.Point{}, Max: image.Point{X: size, Y: size}}
+	// The rectangle dictates the image size, so pixels per module can only be
+	// honoured as an integer scale.
+	mode := q.Scale
+	if mode == ScaleModule {
+		mode = ScaleCenter
+	}
 
-	// Saves a few bytes to have them in this order.
-	p := color.Palette([]color.Color{q.BackgroundColor, q.ForegroundColor})
-	img := image.NewPaletted(rect, p)
+	l := q.layout(size, mode)
 
-	// QR code bitmap.
-	bitmap := q.symbol.bitmap()
+	img, err := q.decorate(q.render(l), l)
+	if err != nil {
+		return err
+	}
 
-	// Map each image pixel to the nearest QR code module.
-	modulesPerPixel := float64(realSize) / float64(size)
+	// The image may be larger than requested if r is too small for the symbol,
+	// in which case its centre is drawn.
+	b := img.Bounds()
+	offset := image.Point{X: (r.Dx() - b.Dx()) / 2, Y: (r.Dy() - b.Dy()) / 2}
 
-	for y := 0; y < size; y++ {
-		y2 := int(float64(y) * modulesPerPixel)
+	placed := b.Sub(b.Min).Add(r.Min).Add(offset)
+	clipped := placed.Intersect(r)
 
-		for x := 0; x < size; x++ {
-			x2 := int(float64(x) * modulesPerPixel)
-			v := bitmap[y2][x2]
+	draw.Draw(dst, clipped, img, b.Min.Add(clipped.Min.Sub(placed.Min)), draw.Over)
 
-			if v {
-				img.Set(x, y, q.ForegroundColor)
-			}
-		}
+	return nil
+}
+
+func (q *QRCode) image(size int) (image.Image, error) {
+	// Build QR code.
+	if err := q.encode(); err != nil {
+		return nil, err
 	}
 
-	return img, nil
+	l := q.rasterSize(size)
+
+	return q.decorate(q.render(l), l)
+}
+
+// rasterSize lays out an image for the size argument of the renderers.
+func (q *QRCode) rasterSize(size int) rasterLayout {
+	mode := q.Scale
+
+	// Variable size support, kept for compatibility with ScaleModule.
+	if size < 0 {
+		size *= -1
+		mode = ScaleModule
+	}
+
+	size, mode = q.physicalSize(size, mode)
+
+	return q.layout(size, mode)
 }
 
 func (q *QRCode) PNG(size int) ([]byte, error) {
@@ -163,6 +282,10 @@ func (q *QRCode) PNG(size int) ([]byte, error) {
 
 	bts := b.Bytes()
 
+	if q.DPI > 0 {
+		bts = pngWithDPI(bts, q.DPI)
+	}
+
 	if q.Base64 {
 		bts = []byte(fmt.Sprintf("data:image/png;base64,%s", base64.StdEncoding.EncodeToString(bts)))
 	}
@@ -178,12 +301,16 @@ func (q *QRCode) JPEG(size int) ([]byte, error) {
 
 	var b bytes.Buffer
 
-	if err := jpeg.Encode(&b, img, &jpeg.Options{Quality: jpeg.DefaultQuality}); err != nil {
+	if err := jpeg.Encode(&b, q.flatten(img), &jpeg.Options{Quality: jpeg.DefaultQuality}); err != nil {
 		return nil, err
 	}
 
 	bts := b.Bytes()
 
+	if q.DPI > 0 {
+		bts = jpegWithDPI(bts, q.DPI)
+	}
+
 	if q.Base64 {
 		bts = []byte(fmt.Sprintf("data:image/jpeg;base64,%s", base64.StdEncoding.EncodeToString(bts)))
 	}
@@ -191,114 +318,74 @@ func (q *QRCode) JPEG(size int) ([]byte, error) {
 	return bts, nil
 }
 
-func (q *QRCode) PDF(size int) ([]byte, error) {
-	img, err := q.image(size)
-	if err != nil {
-		return nil, err
-	}
-
-	var b bytes.Buffer
-
-	pdf := gopdf.GoPdf{}
-
-	rect := gopdf.Rect{W: float64(size), H: float64(size)}
-
-	pdf.Start(gopdf.Config{Unit: gopdf.UnitPT, PageSize: rect})
-	pdf.AddPage()
-
-	if err := pdf.ImageFrom(img, 0, 0, &rect); err != nil {
-		return nil, err
+// matte returns the colour transparency is flattened onto.
+func (q *QRCode) matte() color.Color {
+	if q.Matte == nil {
+		return color.White
 	}
 
-	if err := pdf.Write(&b); err != nil {
-		return nil, err
-	}
+	return q.Matte
+}
 
-	bts := b.Bytes()
+// flatten returns img composited over the matte colour, for formats without
+// transparency, which would otherwise darken translucent pixels.
+func (q *QRCode) flatten(img image.Image) image.Image {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(q.matte()), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
 
-	if q.Base64 {
-		bts = []byte(fmt.Sprintf("data:application/pdf;base64,%s", base64.StdEncoding.EncodeToString(bts)))
-	}
-
-	return bts, nil
+	return flat
 }
 
-func (q *QRCode) SVG(size int) ([]byte, error) {
-	if err := q.encode(); err != nil {
-		return nil, err
+// encodeData pads the data and adds error correction, once.
+func (q *QRCode) encodeData() error {
+	if q.encoded != nil {
+		return nil
 	}
 
-	var b bytes.Buffer
-
-	bgR, bgG, bgB, bgA := q.BackgroundColor.RGBA()
-	bgStyle := fmt.Sprintf("fill: rgb(%d, %d, %d); fill-opacity: %.2f",
-		bgR>>8, bgG>>8, bgB>>8, float64(bgA>>8)/255,
-	)
-
-	fgR, fgG, fgB, fgA := q.ForegroundColor.RGBA()
-	fgStyle := fmt.Sprintf("fill: rgb(%d, %d, %d); fill-opacity: %.2f",
-		fgR>>8, fgG>>8, fgB>>8, float64(fgA>>8)/255,
-	)
-
-	scale := math.Floor(float64(size)/float64(q.symbol.size)) + float64(1)
-	size = int(scale) * q.symbol.size
-
-	svg := svgo.New(&b)
-
-	svg.Start(size, size)
-	svg.Rect(0, 0, size, size, bgStyle)
-	svg.Group(fgStyle)
-	svg.Scale(scale)
-
-	bitmap := q.symbol.bitmap()
+	numTerminatorBits := q.version.numTerminatorBitsRequired(q.data.Len())
 
-	for y := 0; y < q.symbol.size; y++ {
-		for x := 0; x < q.symbol.size; x++ {
-			v := bitmap[y][x]
+	q.addTerminatorBits(numTerminatorBits)
 
-			if v {
-				svg.Rect(x, y, 1, 1)
-			}
-		}
+	if err := q.addPadding(); err != nil {
+		return err
 	}
 
-	svg.Gend()
-	svg.Gend()
-	svg.End()
-
-	bts := b.Bytes()
-
-	if q.Base64 {
-		bts = []byte(fmt.Sprintf("data:image/svg+xml;base64,%s", base64.StdEncoding.EncodeToString(bts)))
+	encoded, err := q.encodeBlocks()
+	if err != nil {
+		return err
 	}
 
-	return bts, nil
+	q.encoded = encoded
+
+	return nil
 }
 
 func (q *QRCode) encode() error {
-	numTerminatorBits := q.version.numTerminatorBitsRequired(q.data.Len())
-
-	q.addTerminatorBits(numTerminatorBits)
-
-	if err := q.addPadding(); err != nil {
+	if err := q.validate(); err != nil {
 		return err
 	}
 
-	encoded, err := q.encodeBlocks()
-	if err != nil {
+	if err := q.encodeData(); err != nil {
 		return err
 	}
 
 	const numMasks int = 8
 
-	penalty := 0
+	// The symbol is rebuilt on every call so option changes such as Margin
+	// and Logo take effect.
+	q.symbol = nil
+
+	var area moduleRect
+
+	penalty, damage := 0, 0
 
 	for mask := 0; mask < numMasks; mask++ {
 		var s *symbol
 
 		var err error
 
-		s, err = buildRegularSymbol(q.version, mask, encoded, q.Margin)
+		s, err = buildRegularSymbol(q.version, mask, q.encoded, q.Margin)
 		if err != nil {
 			return err
 		}
@@ -309,12 +396,34 @@ func (q *QRCode) encode() error {
 				numEmptyModules, q.versionNumber)
 		}
 
-		p := s.penaltyScore()
+		p, d := s.penaltyScore(), 0
+
+		// With a logo, prefer the mask losing the fewest codewords under it.
+		if q.Logo != nil {
+			if mask == 0 {
+				if area, err = q.logoArea(s); err != nil {
+					return err
+				}
+			}
+
+			d = q.logoDamage(s, area)
+		}
 
-		if q.symbol == nil || p < penalty {
+		if q.symbol == nil || d < damage || d == damage && p < penalty {
 			q.symbol = s
 			q.mask = mask
-			penalty = p
+			penalty, damage = p, d
+		}
+	}
+
+	if q.Logo != nil {
+		q.symbol.clear(area)
+
+		q.logoRect = moduleRect{
+			x: area.x + q.LogoPadding,
+			y: area.y + q.LogoPadding,
+			w: area.w - 2*q.LogoPadding,
+			h: area.h - 2*q.LogoPadding,
 		}
 	}
 
@@ -0,0 +1,49 @@
+package qrcode
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestDrawClipsToCentre(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.Scale = ScaleModule
+
+	img, err := q.Image(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := img.Bounds()
+	r := image.Rect(5, 5, 25, 25)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 30, 30))
+
+	if err := q.Draw(dst, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// The symbol doesn't fit, so the middle of the image is drawn.
+	offset := image.Pt((b.Dx()-r.Dx())/2, (b.Dy()-r.Dy())/2)
+
+	want := image.NewRGBA(r)
+	draw.Draw(want, r, img, b.Min.Add(offset), draw.Src)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if got, want := dst.RGBAAt(x, y), want.RGBAAt(x, y); got != want {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// Pixels outside r are left alone.
+	if got := dst.RGBAAt(0, 0); got.A != 0 {
+		t.Errorf("pixel outside r drawn: %v", got)
+	}
+}
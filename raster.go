@@ -0,0 +1,459 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+)
+
+type rasterLayout struct {
+	// Width/height of the image in pixels.
+	size int
+
+	// Pixels per module, or zero if modules are stretched to fit.
+	scale int
+
+	// Pixels between the image edge and the first module.
+	offset int
+
+	// Combined width/height of the symbol and quiet zones, in modules.
+	modules int
+}
+
+func (q *QRCode) layout(size int, mode ScaleMode) rasterLayout {
+	// Minimum pixels (both width and height) required.
+	realSize := q.symbol.size
+
+	l := rasterLayout{modules: realSize}
+
+	switch mode {
+	case ScaleModule:
+		l.scale = size
+		if l.scale < 1 {
+			l.scale = 1
+		}
+
+		l.size = l.scale * realSize
+	case ScaleCenter:
+		l.scale = size / realSize
+		if l.scale < 1 {
+			l.scale = 1
+		}
+
+		l.size = l.scale * realSize
+		if size > l.size {
+			l.offset = (size - l.size) / 2
+			l.size = size
+		}
+	default:
+		// Actual pixels available to draw the symbol. Automatically increase
+		// the image size if it's not large enough.
+		l.size = size
+		if l.size < realSize {
+			l.size = realSize
+		}
+	}
+
+	return l
+}
+
+// module returns the module covering pixel p along either axis, or -1 if p
+// falls in the padding around an integer scaled symbol.
+func (l rasterLayout) module(p int) int {
+	if l.scale == 0 {
+		// Map each image pixel to the nearest QR code module.
+		return int(float64(p) * float64(l.modules) / float64(l.size))
+	}
+
+	if p < l.offset {
+		return -1
+	}
+
+	m := (p - l.offset) / l.scale
+	if m >= l.modules {
+		return -1
+	}
+
+	return m
+}
+
+// Samples per pixel along each axis when anti-aliasing.
+const supersampling = 4
+
+func (q *QRCode) render(l rasterLayout) image.Image {
+	// Anti-aliased images are drawn larger, a band of rows at a time, and
+	// scaled down.
+	k := 1
+	if q.antialias() {
+		k = supersampling
+	}
+
+	sampled := l
+	sampled.size *= k
+	sampled.scale *= k
+	sampled.offset *= k
+
+	// Output image.
+	rect := image.Rectangle{Min: image.Point{}, Max: image.Point{X: l.size, Y: l.size}}
+
+	layers := q.layers()
+
+	// Saves a few bytes to have them in this order.
+	p := color.Palette([]color.Color{q.backgroundColor(), q.over(q.moduleColor())})
+
+	index := make([]uint8, len(layers))
+
+	// Gradient filled modules are marked with an entry of their own, replaced
+	// by the gradient colours once drawn.
+	gradient := -1
+
+	for i, ly := range layers {
+		if q.gradient(ly) == nil {
+			index[i] = paletteIndex(&p, q.over(ly.paint(q)))
+			continue
+		}
+
+		if gradient < 0 {
+			gradient = len(p)
+			p = append(p, q.over(q.moduleColor()))
+		}
+
+		index[i] = uint8(gradient)
+	}
+
+	var fills []func(img *image.Paletted)
+
+	for i, ly := range layers {
+		index := index[i]
+
+		if ly.modules != nil {
+			if isSquare(ly.shape) {
+				modules := ly.modules
+
+				fills = append(fills, func(img *image.Paletted) {
+					fillModules(img, sampled, modules, index)
+				})
+			} else {
+				fills = append(fills, pathFiller(sampled, modulePaths(ly.modules, ly.shape), index))
+			}
+		}
+
+		if len(ly.paths) > 0 {
+			fills = append(fills, pathFiller(sampled, ly.paths, index))
+		}
+	}
+
+	paint := func(img *image.Paletted) {
+		for _, fill := range fills {
+			fill(img)
+		}
+	}
+
+	// Gradients, the logo and blended edges bring their own colours, so the
+	// palette no longer suffices.
+	rgba := make([][4]uint8, len(p))
+	for i, c := range p {
+		r, g, b, a := c.RGBA()
+		rgba[i] = [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+
+	colour := func(out *image.RGBA, img *image.Paletted) {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			src := img.Pix[img.PixOffset(img.Rect.Min.X, y):]
+			dst := out.Pix[out.PixOffset(img.Rect.Min.X, y):]
+
+			for x := 0; x < img.Rect.Dx(); x++ {
+				copy(dst[x*4:x*4+4], rgba[src[x]][:])
+			}
+		}
+
+		if gradient >= 0 {
+			q.fillGradient(out, img, sampled, uint8(gradient))
+		}
+	}
+
+	var out *image.RGBA
+
+	if k == 1 {
+		img := image.NewPaletted(rect, p)
+		paint(img)
+
+		if q.Logo == nil && gradient < 0 {
+			return img
+		}
+
+		out = image.NewRGBA(rect)
+		colour(out, img)
+	} else {
+		img := image.NewPaletted(image.Rect(0, 0, sampled.size, k), p)
+
+		out = supersample(l.size, l.size, k, func(band *image.RGBA) {
+			img.Rect = band.Rect
+			for i := range img.Pix {
+				img.Pix[i] = 0
+			}
+
+			paint(img)
+			colour(band, img)
+		})
+	}
+
+	if q.Logo != nil {
+		q.drawLogo(out, l)
+
+		offset := float64(l.offset)
+		fillRGBA(out, q.logoOverlay(), l.moduleSize(), offset, offset)
+	}
+
+	return out
+}
+
+// antialias reports whether raster renderers smooth edges.
+func (q *QRCode) antialias() bool {
+	switch q.Antialias {
+	case AntialiasNever:
+		return false
+	case AntialiasAlways:
+		return true
+	default:
+		return !q.isPlain()
+	}
+}
+
+// supersample returns a w x h image drawn k times larger by fill. fill is
+// given bands of k rows from the top down, each shrunk into a row of the
+// image, so the larger image is never held whole.
+func supersample(w int, h int, k int, fill func(band *image.RGBA)) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	band := image.NewRGBA(image.Rect(0, 0, w*k, k))
+
+	for y := 0; y < h; y++ {
+		// The band moves down the larger image, reusing its pixels.
+		band.Rect = image.Rect(0, y*k, w*k, (y+1)*k)
+		for i := range band.Pix {
+			band.Pix[i] = 0
+		}
+
+		fill(band)
+		downsample(dst, band, k)
+	}
+
+	return dst
+}
+
+// downsample shrinks src by k along each axis into the pixels of dst it
+// covers, averaging each k x k block of pixels. RGBA pixels are
+// premultiplied, so the average blends alpha correctly.
+func downsample(dst *image.RGBA, src *image.RGBA, k int) {
+	b := src.Bounds()
+	n := uint32(k * k)
+
+	for y := b.Min.Y / k; y < b.Max.Y/k; y++ {
+		for x := 0; x < b.Dx()/k; x++ {
+			var sum [4]uint32
+
+			for sy := y * k; sy < (y+1)*k; sy++ {
+				row := src.Pix[src.PixOffset(x*k, sy):]
+
+				for i := 0; i < k*4; i += 4 {
+					sum[0] += uint32(row[i])
+					sum[1] += uint32(row[i+1])
+					sum[2] += uint32(row[i+2])
+					sum[3] += uint32(row[i+3])
+				}
+			}
+
+			p := dst.Pix[dst.PixOffset(x, y):]
+			for i := range sum {
+				p[i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+}
+
+// fillGradient colours the pixels of out set to index i in img with the
+// foreground gradient.
+func (q *QRCode) fillGradient(out *image.RGBA, img *image.Paletted, l rasterLayout, i uint8) {
+	g := q.ForegroundGradient
+	m := l.moduleSize()
+	n := float64(q.symbol.symbolSize)
+
+	// Pixel centres in symbol coordinates, quiet zone excluded.
+	origin := float64(l.offset) + float64(q.symbol.quietZoneSize)*m
+
+	b := img.Bounds()
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		sy := (float64(y) + 0.5 - origin) / m
+
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.ColorIndexAt(x, y) != i {
+				continue
+			}
+
+			sx := (float64(x) + 0.5 - origin) / m
+
+			out.Set(x, y, q.over(g.at(g.offset(sx, sy, n))))
+		}
+	}
+}
+
+// over returns c composited over the background colour, so translucent
+// modules show the background rather than whatever the image is drawn on. A
+// transparent background leaves c unchanged.
+func (q *QRCode) over(c color.Color) color.Color {
+	return composite(c, q.backgroundColor())
+}
+
+// composite returns c composited over b.
+func composite(c color.Color, b color.Color) color.RGBA64 {
+	fr, fg, fb, fa := c.RGBA()
+	br, bg, bb, ba := b.RGBA()
+
+	// Porter-Duff source over destination, in premultiplied components.
+	blend := func(f uint32, b uint32) uint16 {
+		return uint16(f + b*(0xffff-fa)/0xffff)
+	}
+
+	return color.RGBA64{R: blend(fr, br), G: blend(fg, bg), B: blend(fb, bb), A: blend(fa, ba)}
+}
+
+// isTransparent reports whether c is fully transparent.
+func isTransparent(c color.Color) bool {
+	_, _, _, a := c.RGBA()
+
+	return a == 0
+}
+
+// paletteIndex returns the index of c in p, adding it if missing.
+func paletteIndex(p *color.Palette, c color.Color) uint8 {
+	r, g, b, a := c.RGBA()
+
+	for i, pc := range *p {
+		pr, pg, pb, pa := pc.RGBA()
+		if r == pr && g == pg && b == pb && a == pa {
+			return uint8(i)
+		}
+	}
+
+	*p = append(*p, c)
+
+	return uint8(len(*p) - 1)
+}
+
+// fillModules sets the pixels of img mapped to set modules of bitmap to
+// colour index i.
+func fillModules(img *image.Paletted, l rasterLayout, bitmap [][]bool, i uint8) {
+	b := img.Bounds()
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		y2 := l.module(y)
+		if y2 < 0 {
+			continue
+		}
+
+		for x := b.Min.X; x < b.Max.X; x++ {
+			x2 := l.module(x)
+			if x2 < 0 {
+				continue
+			}
+
+			if bitmap[y2][x2] {
+				img.SetColorIndex(x, y, i)
+			}
+		}
+	}
+}
+
+// moduleSize returns the size of a module in pixels.
+func (l rasterLayout) moduleSize() float64 {
+	if l.scale == 0 {
+		return float64(l.size) / float64(l.modules)
+	}
+
+	return float64(l.scale)
+}
+
+// fillRGBA composites fills, in module coordinates scaled by s and offset by
+// (dx, dy), over img.
+func fillRGBA(img *image.RGBA, fills []pathFill, s float64, dx float64, dy float64) {
+	rgbaFiller(fills, s, dx, dy)(img)
+}
+
+// rgbaFiller returns a function compositing fills like fillRGBA over the rows
+// of an image, which may be a band moving down a larger one.
+func rgbaFiller(fills []pathFill, s float64, dx float64, dy float64) func(img *image.RGBA) {
+	scanners := make([]*scanner, len(fills))
+	for i, f := range fills {
+		scanners[i] = newScanner(f.path.polygons(s, dx, dy))
+	}
+
+	return func(img *image.RGBA) {
+		b := img.Bounds()
+
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			row := img.Pix[img.PixOffset(b.Min.X, y):]
+
+			for i, f := range fills {
+				r, g, bl, a := f.color.RGBA()
+
+				// Source over, in premultiplied components.
+				blend := func(s uint32, d uint8) uint8 {
+					return uint8((s + uint32(d)*0x101*(0xffff-a)/0xffff) >> 8)
+				}
+
+				// Opaque fills replace what is under them.
+				c := [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(bl >> 8), uint8(a >> 8)}
+
+				scanners[i].scan(y, b.Dx(), func(x0 int, x1 int) {
+					for x := x0; x < x1; x++ {
+						px := row[x*4 : x*4+4]
+
+						if a == 0xffff {
+							copy(px, c[:])
+						} else {
+							px[0], px[1], px[2], px[3] = blend(r, px[0]), blend(g, px[1]), blend(bl, px[2]), blend(a, px[3])
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+// fillPaths sets the pixels of img covered by paths, given in module
+// coordinates, to colour index i.
+func fillPaths(img *image.Paletted, l rasterLayout, paths []Path, i uint8) {
+	if len(paths) == 0 {
+		return
+	}
+
+	pathFiller(l, paths, i)(img)
+}
+
+// pathFiller returns a function filling paths like fillPaths over the rows of
+// an image, which may be a band moving down a larger one.
+func pathFiller(l rasterLayout, paths []Path, i uint8) func(img *image.Paletted) {
+	var polygons [][]vec
+
+	offset := float64(l.offset)
+
+	for _, p := range paths {
+		polygons = append(polygons, p.polygons(l.moduleSize(), offset, offset)...)
+	}
+
+	s := newScanner(polygons)
+
+	return func(img *image.Paletted) {
+		b := img.Bounds()
+
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			row := img.Pix[img.PixOffset(b.Min.X, y):]
+
+			s.scan(y, b.Dx(), func(x0 int, x1 int) {
+				for x := x0; x < x1; x++ {
+					row[x] = i
+				}
+			})
+		}
+	}
+}
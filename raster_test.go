@@ -0,0 +1,43 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSupersampleMatchesWholeImage(t *testing.T) {
+	var circle, square Path
+
+	circle.Circle(5, 4, 3)
+	square.Rect(1, 1, 4, 6)
+
+	fills := []pathFill{
+		{path: circle, color: color.RGBA{R: 200, A: 255}},
+		{path: square, color: color.RGBA{B: 100, A: 128}},
+	}
+
+	const w, h, k, s = 23, 19, 4, 2.0
+
+	// Drawn whole at k times the size, then shrunk.
+	large := image.NewRGBA(image.Rect(0, 0, w*k, h*k))
+	fillRGBA(large, fills, s*k, 0, 0)
+
+	want := image.NewRGBA(image.Rect(0, 0, w, h))
+	downsample(want, large, k)
+
+	got := supersample(w, h, k, rgbaFiller(fills, s*k, 0, 0))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if got.RGBAAt(x, y) != want.RGBAAt(x, y) {
+				t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got.RGBAAt(x, y), want.RGBAAt(x, y))
+			}
+		}
+	}
+
+	// Curved edges are blended.
+	if a := got.RGBAAt(14, 3).A; a == 0 || a == 255 {
+		t.Errorf("edge pixel alpha %d, want partial", a)
+	}
+}
@@ -12,6 +12,9 @@ type regularSymbol struct {
 
 	symbol *symbol
 	size   int
+
+	// Codewords in the data stream; the bits after them are remainder bits.
+	numCodewords int
 }
 
 const (
@@ -108,6 +111,8 @@ func buildRegularSymbol(version qrCodeVersion, mask int, data *bitset.Bitset, qu
 
 		symbol: newSymbol(version.symbolSize(), quietZoneSize),
 		size:   version.symbolSize(),
+
+		numCodewords: version.numCodewords(),
 	}
 
 	m.addFinderPatterns()
@@ -132,26 +137,36 @@ func buildRegularSymbol(version qrCodeVersion, mask int, data *bitset.Bitset, qu
 
 func (m *regularSymbol) addFinderPatterns() {
 	fpSize := finderPatternSize
-	fp := finderPattern
 	fpHBorder := finderPatternHorizontalBorder
 	fpVBorder := finderPatternVerticalBorder
 
 	// Top left Finder Pattern.
-	m.symbol.set2dPattern(0, 0, fp)
+	m.addFinderPattern(0, 0)
 	m.symbol.set2dPattern(0, fpSize, fpHBorder)
 	m.symbol.set2dPattern(fpSize, 0, fpVBorder)
 
 	// Top right Finder Pattern.
-	m.symbol.set2dPattern(m.size-fpSize, 0, fp)
+	m.addFinderPattern(m.size-fpSize, 0)
 	m.symbol.set2dPattern(m.size-fpSize-1, fpSize, fpHBorder)
 	m.symbol.set2dPattern(m.size-fpSize-1, 0, fpVBorder)
 
 	// Bottom left Finder Pattern.
-	m.symbol.set2dPattern(0, m.size-fpSize, fp)
+	m.addFinderPattern(0, m.size-fpSize)
 	m.symbol.set2dPattern(0, m.size-fpSize-1, fpHBorder)
 	m.symbol.set2dPattern(fpSize, m.size-fpSize-1, fpVBorder)
 }
 
+func (m *regularSymbol) addFinderPattern(x int, y int) {
+	m.symbol.set2dPattern(x, y, finderPattern)
+
+	// Keep track of the pattern so it can be styled when rendering.
+	m.symbol.markPattern(x, y, finderPatternSize, finderPatternSize, patternFinder)
+	m.symbol.markPattern(x+2, y+2, finderPatternSize-4, finderPatternSize-4, patternFinderEye)
+
+	q := m.symbol.quietZoneSize
+	m.symbol.finders = append(m.symbol.finders, modulePoint{x: x + q, y: y + q})
+}
+
 func (m *regularSymbol) addAlignmentPatterns() {
 	for _, x := range alignmentPatternCenter[m.version.version] {
 		for _, y := range alignmentPatternCenter[m.version.version] {
@@ -160,6 +175,10 @@ func (m *regularSymbol) addAlignmentPatterns() {
 			}
 
 			m.symbol.set2dPattern(x-2, y-2, alignmentPattern)
+			m.symbol.markPattern(x-2, y-2, len(alignmentPattern), len(alignmentPattern), patternAlignment)
+
+			q := m.symbol.quietZoneSize
+			m.symbol.alignments = append(m.symbol.alignments, modulePoint{x: x - 2 + q, y: y - 2 + q})
 		}
 	}
 }
@@ -330,6 +349,11 @@ func (m *regularSymbol) addData() (bool, error) {
 
 		m.symbol.set(x+xOffset, y, mask != bo)
 
+		if i/8 < m.numCodewords {
+			q := m.symbol.quietZoneSize
+			m.symbol.codewords[y+q][x+xOffset+q] = i / 8
+		}
+
 		if i == m.data.Len()-1 {
 			break
 		}
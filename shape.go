@@ -0,0 +1,155 @@
+package qrcode
+
+// Neighbours records which of the four modules adjacent to a dark module are
+// also dark.
+type Neighbours uint8
+
+const (
+	NeighbourTop Neighbours = 1 << iota
+	NeighbourRight
+	NeighbourBottom
+	NeighbourLeft
+)
+
+// Shape draws the dark modules of a QR Code.
+type Shape interface {
+	// Module returns the outline of a dark module, in module coordinates.
+	Module(n Neighbours) Path
+}
+
+// ShapeFunc adapts a function to the Shape interface.
+type ShapeFunc func(n Neighbours) Path
+
+func (f ShapeFunc) Module(n Neighbours) Path {
+	return f(n)
+}
+
+var (
+	// ShapeSquare fills the whole module. It is the default.
+	ShapeSquare Shape = squareShape{}
+	// ShapeCircle draws a dot touching the module edges.
+	ShapeCircle Shape = circleShape{}
+	// ShapeRounded draws a square with rounded corners.
+	ShapeRounded Shape = roundedShape{radius: 0.3}
+	// ShapeLiquid rounds only the corners not shared with a dark neighbour,
+	// so adjacent modules join into smooth runs.
+	ShapeLiquid Shape = liquidShape{}
+	// ShapeDiamond draws a square rotated by 45 degrees.
+	ShapeDiamond Shape = diamondShape{}
+)
+
+type squareShape struct{}
+
+func (squareShape) Module(Neighbours) Path {
+	var p Path
+	p.Rect(0, 0, 1, 1)
+
+	return p
+}
+
+type circleShape struct{}
+
+func (circleShape) Module(Neighbours) Path {
+	var p Path
+	p.Circle(0.5, 0.5, 0.5)
+
+	return p
+}
+
+type roundedShape struct {
+	radius float64
+}
+
+func (s roundedShape) Module(Neighbours) Path {
+	var p Path
+	p.RoundedRect(0, 0, 1, 1, s.radius, s.radius, s.radius, s.radius)
+
+	return p
+}
+
+type liquidShape struct{}
+
+func (liquidShape) Module(n Neighbours) Path {
+	const r = 0.5
+
+	corner := func(a Neighbours, b Neighbours) float64 {
+		if n&(a|b) == 0 {
+			return r
+		}
+
+		return 0
+	}
+
+	var p Path
+	p.RoundedRect(0, 0, 1, 1,
+		corner(NeighbourTop, NeighbourLeft),
+		corner(NeighbourTop, NeighbourRight),
+		corner(NeighbourBottom, NeighbourRight),
+		corner(NeighbourBottom, NeighbourLeft),
+	)
+
+	return p
+}
+
+type diamondShape struct{}
+
+func (diamondShape) Module(Neighbours) Path {
+	var p Path
+	p.MoveTo(0.5, 0)
+	p.LineTo(1, 0.5)
+	p.LineTo(0.5, 1)
+	p.LineTo(0, 0.5)
+	p.Close()
+
+	return p
+}
+
+// isSquare reports whether s draws plain square modules, which renderers
+// merge into larger rectangles.
+func isSquare(s Shape) bool {
+	return s == nil || s == ShapeSquare
+}
+
+// neighbours returns the dark neighbours of the module at [y][x] of bitmap.
+func neighbours(bitmap [][]bool, x int, y int) Neighbours {
+	var n Neighbours
+
+	if y > 0 && bitmap[y-1][x] {
+		n |= NeighbourTop
+	}
+
+	if x+1 < len(bitmap[y]) && bitmap[y][x+1] {
+		n |= NeighbourRight
+	}
+
+	if y+1 < len(bitmap) && bitmap[y+1][x] {
+		n |= NeighbourBottom
+	}
+
+	if x > 0 && bitmap[y][x-1] {
+		n |= NeighbourLeft
+	}
+
+	return n
+}
+
+// modulePaths returns the outline of every set module of bitmap drawn with
+// shape, in module coordinates of the bitmap.
+func modulePaths(bitmap [][]bool, shape Shape) []Path {
+	var paths []Path
+
+	for y := range bitmap {
+		for x := range bitmap[y] {
+			if !bitmap[y][x] {
+				continue
+			}
+
+			var p Path
+			p.Append(shape.Module(neighbours(bitmap, x, y)), float64(x), float64(y))
+
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,346 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/signintech/gopdf"
+)
+
+// SheetTemplate is a grid of equally sized labels on a page, such as a sheet
+// of adhesive labels.
+type SheetTemplate struct {
+	PageWidth  Length
+	PageHeight Length
+
+	// Distance from the top left corner of the page to the first label.
+	MarginTop  Length
+	MarginLeft Length
+
+	LabelWidth  Length
+	LabelHeight Length
+
+	// Space between adjacent labels.
+	GutterX Length
+	GutterY Length
+
+	Columns int
+	Rows    int
+}
+
+var (
+	// Avery5160 is a US Letter sheet of 3 x 10 labels of 2⅝ x 1 inches.
+	Avery5160 = SheetTemplate{
+		PageWidth: 8.5 * Inch, PageHeight: 11 * Inch,
+		MarginTop: 0.5 * Inch, MarginLeft: 0.1875 * Inch,
+		LabelWidth: 2.625 * Inch, LabelHeight: 1 * Inch,
+		GutterX: 0.125 * Inch,
+		Columns: 3, Rows: 10,
+	}
+
+	// AveryL7160 is an A4 sheet of 3 x 7 labels of 63.5 x 38.1 millimetres.
+	AveryL7160 = SheetTemplate{
+		PageWidth: 210 * Millimeter, PageHeight: 297 * Millimeter,
+		MarginTop: 15.15 * Millimeter, MarginLeft: 7.21 * Millimeter,
+		LabelWidth: 63.5 * Millimeter, LabelHeight: 38.1 * Millimeter,
+		GutterX: 2.54 * Millimeter,
+		Columns: 3, Rows: 7,
+	}
+)
+
+// Label is a label of a Sheet.
+type Label struct {
+	// QR Code printed on the label, none if nil. Its physical size options
+	// are ignored, the code being fitted to the label.
+	Code *QRCode
+
+	// Text printed beside the code, or below it on labels taller than wide.
+	// Lines are separated by newlines, and shrunk to fit the label.
+	Text string
+}
+
+// Sheet prints labels on pages laid out by a template, filling each page row
+// by row.
+type Sheet struct {
+	Template SheetTemplate
+	Labels   []Label
+
+	// Space kept clear inside the edges of each label.
+	Padding Length
+
+	// Size of the text, 10 points if 0, and its colour, black if nil.
+	FontSize  Length
+	TextColor color.Color
+
+	// Number of labels left blank at the start of the first page, to print
+	// on a partly used sheet.
+	Skip int
+
+	// CropMarks draws cutting marks in the page margins, in line with the
+	// label edges.
+	CropMarks bool
+}
+
+// Length of crop marks, and their distance from the labels.
+const (
+	cropMarkLength = 12 * Point
+	cropMarkOffset = 3 * Point
+)
+
+// PDF returns the labels as a PDF document with as many pages as they need.
+func (s *Sheet) PDF() ([]byte, error) {
+	t := s.Template
+
+	if t.Columns < 1 || t.Rows < 1 {
+		return nil, errors.New("sheet template needs at least one column and row")
+	}
+
+	if t.PageWidth <= 0 || t.PageHeight <= 0 || t.LabelWidth <= 0 || t.LabelHeight <= 0 {
+		return nil, errors.New("sheet template sizes must be positive")
+	}
+
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{Unit: gopdf.UnitPT, PageSize: gopdf.Rect{W: t.PageWidth.Points(), H: t.PageHeight.Points()}})
+
+	skip := s.Skip
+	if skip < 0 {
+		skip = 0
+	}
+
+	perPage := t.Columns * t.Rows
+
+	// Blank positions still fill pages, and an empty sheet still has one.
+	total := skip + len(s.Labels)
+	if total == 0 {
+		total = 1
+	}
+
+	for i := 0; i < total; i++ {
+		n := i % perPage
+
+		if n == 0 {
+			pdf.AddPage()
+
+			if s.CropMarks {
+				s.cropMarks(&pdf)
+			}
+		}
+
+		j := i - skip
+		if j < 0 || j >= len(s.Labels) {
+			continue
+		}
+
+		x := t.MarginLeft + Length(n%t.Columns)*(t.LabelWidth+t.GutterX)
+		y := t.MarginTop + Length(n/t.Columns)*(t.LabelHeight+t.GutterY)
+
+		if err := s.drawLabel(&pdf, s.Labels[j], x.Points(), y.Points()); err != nil {
+			return nil, fmt.Errorf("label %d: %w", j, err)
+		}
+	}
+
+	var b bytes.Buffer
+
+	if err := pdf.Write(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// drawLabel draws lb on the label with its top left corner at (x, y).
+func (s *Sheet) drawLabel(pdf *gopdf.GoPdf, lb Label, x float64, y float64) error {
+	p := s.Padding.Points()
+
+	// Box inside the padding.
+	x, y = x+p, y+p
+	w := s.Template.LabelWidth.Points() - 2*p
+	h := s.Template.LabelHeight.Points() - 2*p
+
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	size := s.FontSize.Points()
+	if size <= 0 {
+		size = 10
+	}
+
+	var lines []string
+	if lb.Text != "" {
+		lines = strings.Split(lb.Text, "\n")
+	}
+
+	// Height of the code per unit of width.
+	aspect := 1.0
+
+	if lb.Code != nil {
+		var err error
+
+		if aspect, err = lb.Code.pdfHeight(1); err != nil {
+			return err
+		}
+	}
+
+	// Text goes beside the code on wide labels, and below it otherwise.
+	beside := w >= h
+
+	codeWidth := 0.0
+	textX, textWidth := x, w
+
+	if lb.Code != nil && beside {
+		codeWidth = math.Min(h/aspect, w)
+
+		if len(lines) > 0 {
+			// Keep at least half the label for the text.
+			codeWidth = math.Min(codeWidth, w/2)
+
+			gap := math.Max(p, size/2)
+			textX, textWidth = x+codeWidth+gap, w-codeWidth-gap
+		}
+	}
+
+	text, textHeight, err := sheetText(lines, size, textWidth)
+	if err != nil {
+		return err
+	}
+
+	if lb.Code != nil {
+		cx, cy := x, y
+
+		if beside {
+			cy += (h - codeWidth*aspect) / 2
+		} else {
+			codeWidth = math.Min(w, math.Max(h-textHeight, 0)/aspect)
+			cx += (w - codeWidth) / 2
+		}
+
+		if codeWidth > 0 {
+			if err := lb.Code.DrawPDF(pdf, cx, cy, codeWidth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(text) == 0 {
+		return nil
+	}
+
+	c := s.TextColor
+	if c == nil {
+		c = color.Black
+	}
+
+	if err := setPDFFill(pdf, c); err != nil {
+		return err
+	}
+
+	defer pdf.ClearTransparency()
+
+	// Text beside the code is left aligned and centred vertically, text below
+	// it or alone centred horizontally at the bottom or middle.
+	ty := y + (h-textHeight)/2
+	if lb.Code != nil && !beside {
+		ty = y + h - textHeight
+	}
+
+	for _, line := range text {
+		tx := textX
+		if lb.Code == nil || !beside {
+			tx += (textWidth - line.width) / 2
+		}
+
+		pdfPath(pdf, line.path, 1, tx, ty+line.baseline)
+
+		ty += line.height
+	}
+
+	return nil
+}
+
+// sheetText lays out lines of text at size, each shrunk to maxWidth, and
+// returns their total height.
+func sheetText(lines []string, size float64, maxWidth float64) ([]caption, float64, error) {
+	if maxWidth <= 0 {
+		return nil, 0, nil
+	}
+
+	var text []caption
+
+	height := 0.0
+
+	for _, line := range lines {
+		// A space keeps the height of blank lines.
+		if line == "" {
+			line = " "
+		}
+
+		c, err := captionPath(Caption{Text: line, Size: size}, maxWidth)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		text = append(text, c)
+		height += c.height
+	}
+
+	return text, height, nil
+}
+
+// cropMarks draws marks in the page margins in line with the label edges.
+func (s *Sheet) cropMarks(pdf *gopdf.GoPdf) {
+	t := s.Template
+
+	pdf.SetLineWidth(0.25)
+	pdf.SetStrokeColor(0, 0, 0)
+
+	edges := func(margin Length, size Length, gutter Length, n int) []float64 {
+		var e []float64
+
+		for i := 0; i < n; i++ {
+			start := margin + Length(i)*(size+gutter)
+
+			// Labels without a gutter share their edges.
+			if len(e) == 0 || e[len(e)-1] != start.Points() {
+				e = append(e, start.Points())
+			}
+
+			e = append(e, (start + size).Points())
+		}
+
+		return e
+	}
+
+	xs := edges(t.MarginLeft, t.LabelWidth, t.GutterX, t.Columns)
+	ys := edges(t.MarginTop, t.LabelHeight, t.GutterY, t.Rows)
+
+	// The grid, and the page area around it left for marks.
+	left, right := xs[0]-cropMarkOffset.Points(), xs[len(xs)-1]+cropMarkOffset.Points()
+	top, bottom := ys[0]-cropMarkOffset.Points(), ys[len(ys)-1]+cropMarkOffset.Points()
+	pw, ph := t.PageWidth.Points(), t.PageHeight.Points()
+	l := cropMarkLength.Points()
+
+	for _, x := range xs {
+		if top > 0 {
+			pdf.Line(x, math.Max(top-l, 0), x, top)
+		}
+
+		if bottom < ph {
+			pdf.Line(x, bottom, x, math.Min(bottom+l, ph))
+		}
+	}
+
+	for _, y := range ys {
+		if left > 0 {
+			pdf.Line(math.Max(left-l, 0), y, left, y)
+		}
+
+		if right < pw {
+			pdf.Line(right, y, math.Min(right+l, pw), y)
+		}
+	}
+}
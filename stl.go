@@ -0,0 +1,420 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// STLOptions set the dimensions and encoding of STL output. Defaults are
+// used for lengths not set.
+type STLOptions struct {
+	// Thickness of the base plate.
+	Base Length
+
+	// Height the dark modules are raised above the plate.
+	Height Length
+
+	// ASCII writes the mesh as text instead of binary.
+	ASCII bool
+}
+
+// Defaults of the STL options.
+const (
+	defaultSTLBase   = 2 * Millimeter
+	defaultSTLHeight = 1 * Millimeter
+)
+
+// STL returns the QR Code as an STL mesh in millimetres: a plate width wide,
+// quiet zone included, and opts.Base thick, with the dark modules raised by
+// opts.Height. The mesh is a single closed solid, with each region of
+// adjacent dark or light modules a single face. Width and ModuleSize
+// override width when set.
+func (q *QRCode) STL(width Length, opts STLOptions) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	width, err := q.vectorWidth(width)
+	if err != nil {
+		return nil, err
+	}
+
+	base, height := opts.Base, opts.Height
+
+	if base <= 0 {
+		base = defaultSTLBase
+	}
+
+	if height <= 0 {
+		height = defaultSTLHeight
+	}
+
+	mm := func(l Length) float64 {
+		return l.Points() / Millimeter.Points()
+	}
+
+	n := q.symbol.size
+	size := mm(width)
+
+	triangles := stlMesh(q.markedBitmap(), size/float64(n), mm(base), mm(base+height))
+
+	if opts.ASCII {
+		return stlASCII(triangles), nil
+	}
+
+	return stlBinary(triangles), nil
+}
+
+// stlTriangle is a triangle with its vertices anticlockwise seen from
+// outside.
+type stlTriangle [3][3]float64
+
+// normal returns the outward unit normal of t.
+func (t stlTriangle) normal() [3]float64 {
+	var u, v [3]float64
+
+	for i := range u {
+		u[i] = t[1][i] - t[0][i]
+		v[i] = t[2][i] - t[0][i]
+	}
+
+	c := [3]float64{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+
+	l := math.Sqrt(c[0]*c[0] + c[1]*c[1] + c[2]*c[2])
+	if l == 0 {
+		return c
+	}
+
+	return [3]float64{c[0] / l, c[1] / l, c[2] / l}
+}
+
+// stlPinch is the distance, in modules, by which the corners of dark modules
+// meeting only diagonally are pulled apart, so that no edge of the mesh is
+// shared by more than two faces. A power of two keeps the corners exact, so
+// that corners in line stay in line when cut into triangles.
+const stlPinch = 1.0 / 64
+
+// stlMesh returns the triangles of a plate with the marked modules of bitmap
+// raised, modules m wide, light modules base and dark ones top high. Each
+// region of dark or light modules is one face bounded by its outlines, so
+// the plate top is a single face with holes under the raised regions. Walls
+// are only made where the height drops, to a light region or the edge of the
+// plate.
+func stlMesh(bitmap [][]bool, m float64, base float64, top float64) []stlTriangle {
+	n := len(bitmap)
+
+	light := make([][]bool, n)
+	for y, row := range bitmap {
+		light[y] = make([]bool, len(row))
+
+		for x, v := range row {
+			light[y][x] = !v
+		}
+	}
+
+	// Dark modules form regions with their side neighbours. Light modules
+	// also do with their corner neighbours, filling the gap left where dark
+	// modules meet only at a corner.
+	region := make([][]int, n)
+	for y := range region {
+		region[y] = make([]int, n)
+	}
+
+	var regions []bool
+
+	for y := range bitmap {
+		for x := range bitmap[y] {
+			if region[y][x] == 0 {
+				regions = append(regions, bitmap[y][x])
+				stlFlood(bitmap, region, x, y, len(regions))
+			}
+		}
+	}
+
+	// pinch reports whether only two diagonally opposite modules around the
+	// corner (x, y) are dark.
+	pinch := func(x, y int) bool {
+		if x <= 0 || y <= 0 || x >= n || y >= n {
+			return false
+		}
+
+		a, b, c, d := bitmap[y-1][x-1], bitmap[y-1][x], bitmap[y][x-1], bitmap[y][x]
+
+		return a == d && b == c && a != b
+	}
+
+	// Outlines of each region, in module coordinates with y pointing up.
+	rings := make([][][][2]float64, len(regions))
+
+	for _, join := range []bool{false, true} {
+		b := bitmap
+		if join {
+			b = light
+		}
+
+		for _, outline := range trace(b, join) {
+			// The module on the right of the first edge is in the region.
+			p, q := outline[0], outline[1]
+
+			x, y := p.x, p.y
+
+			switch {
+			case q.x < p.x:
+				x, y = p.x-1, p.y-1
+			case q.y > p.y:
+				x = p.x - 1
+			case q.y < p.y:
+				y = p.y - 1
+			}
+
+			r := region[y][x] - 1
+			rings[r] = append(rings[r], stlRing(outline, n, pinch))
+		}
+	}
+
+	at := func(p [2]float64, z float64) [3]float64 {
+		return [3]float64{p[0] * m, p[1] * m, z}
+	}
+
+	var triangles []stlTriangle
+
+	quad := func(a, b, c, d [3]float64) {
+		triangles = append(triangles, stlTriangle{a, b, c}, stlTriangle{a, c, d})
+	}
+
+	onEdge := func(p [2]float64) bool {
+		return p[0] == 0 || p[1] == 0 || p[0] == float64(n) || p[1] == float64(n)
+	}
+
+	var edge [][2]float64
+
+	for r, marked := range regions {
+		h := base
+		if marked {
+			h = top
+		}
+
+		// The outer outline runs anticlockwise and holes clockwise, keeping
+		// the region on their left.
+		outer := 0
+
+		for i, ring := range rings[r] {
+			if math.Abs(stlArea(ring)) > math.Abs(stlArea(rings[r][outer])) {
+				outer = i
+			}
+		}
+
+		rings[r][0], rings[r][outer] = rings[r][outer], rings[r][0]
+
+		var points [][2]float64
+
+		for i, ring := range rings[r] {
+			if (stlArea(ring) > 0) != (i == 0) {
+				for a, b := 0, len(ring)-1; a < b; a, b = a+1, b-1 {
+					ring[a], ring[b] = ring[b], ring[a]
+				}
+			}
+
+			points = append(points, ring...)
+		}
+
+		for _, t := range triangulate(rings[r]) {
+			triangles = append(triangles, stlTriangle{at(points[t[0]], h), at(points[t[1]], h), at(points[t[2]], h)})
+		}
+
+		for _, ring := range rings[r] {
+			for i, p := range ring {
+				q := ring[(i+1)%len(ring)]
+
+				if onEdge(p) {
+					edge = append(edge, p)
+				}
+
+				// Edges along the edge of the plate lie on the same side.
+				perimeter := p[0] == q[0] && (p[0] == 0 || p[0] == float64(n)) ||
+					p[1] == q[1] && (p[1] == 0 || p[1] == float64(n))
+
+				low := base
+
+				switch {
+				case perimeter:
+					low = 0
+				case !marked:
+					continue
+				}
+
+				// Walls at the edge of the plate are split at its top, where
+				// the walls of lower neighbours end.
+				if low < base && h > base {
+					quad(at(p, low), at(q, low), at(q, base), at(p, base))
+					low = base
+				}
+
+				quad(at(p, low), at(q, low), at(q, h), at(p, h))
+			}
+		}
+	}
+
+	// The bottom is fanned from its centre to the corners of the outlines
+	// along the edge of the plate, in order anticlockwise.
+	s := float64(n)
+	edge = append(edge, [2]float64{0, 0}, [2]float64{s, 0}, [2]float64{s, s}, [2]float64{0, s})
+
+	along := func(p [2]float64) float64 {
+		switch {
+		case p[1] == 0:
+			return p[0]
+		case p[0] == s:
+			return s + p[1]
+		case p[1] == s:
+			return 3*s - p[0]
+		default:
+			return 4*s - p[1]
+		}
+	}
+
+	sort.Slice(edge, func(i, j int) bool {
+		return along(edge[i]) < along(edge[j])
+	})
+
+	var bottom [][2]float64
+
+	for _, p := range edge {
+		if len(bottom) == 0 || bottom[len(bottom)-1] != p {
+			bottom = append(bottom, p)
+		}
+	}
+
+	centre := [2]float64{s / 2, s / 2}
+
+	for k, p := range bottom {
+		triangles = append(triangles, stlTriangle{at(centre, 0), at(bottom[(k+1)%len(bottom)], 0), at(p, 0)})
+	}
+
+	return triangles
+}
+
+// stlRing converts outline to y pointing up. Corners where dark modules meet
+// only diagonally are pulled into the module the outline turns around.
+func stlRing(outline []modulePoint, n int, pinch func(x, y int) bool) [][2]float64 {
+	ring := make([][2]float64, len(outline))
+
+	sign := func(v int) float64 {
+		switch {
+		case v > 0:
+			return 1
+		case v < 0:
+			return -1
+		default:
+			return 0
+		}
+	}
+
+	for i, v := range outline {
+		x, y := float64(v.x), float64(v.y)
+
+		if pinch(v.x, v.y) {
+			a, b := outline[(i+len(outline)-1)%len(outline)], outline[(i+1)%len(outline)]
+			x += stlPinch * (sign(a.x-v.x) + sign(b.x-v.x))
+			y += stlPinch * (sign(a.y-v.y) + sign(b.y-v.y))
+		}
+
+		ring[i] = [2]float64{x, float64(n) - y}
+	}
+
+	return ring
+}
+
+// stlArea returns twice the area of ring, positive when anticlockwise.
+func stlArea(ring [][2]float64) float64 {
+	a := 0.0
+
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+
+	return a
+}
+
+// stlFlood numbers the region of the module (x, y) id: modules of the same
+// colour beside it, and light ones at its corners too.
+func stlFlood(bitmap [][]bool, region [][]int, x int, y int, id int) {
+	dark := bitmap[y][x]
+	stack := []modulePoint{{x, y}}
+	region[y][x] = id
+
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dark && dx != 0 && dy != 0 {
+					continue
+				}
+
+				x, y := p.x+dx, p.y+dy
+
+				if y >= 0 && x >= 0 && y < len(bitmap) && x < len(bitmap[y]) && bitmap[y][x] == dark && region[y][x] == 0 {
+					region[y][x] = id
+					stack = append(stack, modulePoint{x, y})
+				}
+			}
+		}
+	}
+}
+
+// stlBinary encodes triangles as a binary STL file.
+func stlBinary(triangles []stlTriangle) []byte {
+	b := make([]byte, 84+50*len(triangles))
+
+	copy(b, "qrcode")
+	binary.LittleEndian.PutUint32(b[80:], uint32(len(triangles)))
+
+	p := b[84:]
+
+	for _, t := range triangles {
+		nv := t.normal()
+
+		values := nv[:]
+		for _, v := range t {
+			values = append(values, v[:]...)
+		}
+
+		for i, v := range values {
+			binary.LittleEndian.PutUint32(p[4*i:], math.Float32bits(float32(v)))
+		}
+
+		// The attribute byte count after the 12 values stays zero.
+		p = p[50:]
+	}
+
+	return b
+}
+
+// stlASCII encodes triangles as an ASCII STL file.
+func stlASCII(triangles []stlTriangle) []byte {
+	var b bytes.Buffer
+
+	b.WriteString("solid qrcode\n")
+
+	for _, t := range triangles {
+		nv := t.normal()
+
+		fmt.Fprintf(&b, "facet normal %s %s %s\nouter loop\n", svgNumber(nv[0]), svgNumber(nv[1]), svgNumber(nv[2]))
+
+		for _, v := range t {
+			fmt.Fprintf(&b, "vertex %s %s %s\n", svgNumber(v[0]), svgNumber(v[1]), svgNumber(v[2]))
+		}
+
+		b.WriteString("endloop\nendfacet\n")
+	}
+
+	b.WriteString("endsolid qrcode\n")
+
+	return b.Bytes()
+}
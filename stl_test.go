@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSTLMeshIsClosed(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.encode(); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := make([][]bool, 6)
+	for y := range checker {
+		checker[y] = make([]bool, 6)
+		for x := range checker[y] {
+			checker[y][x] = (x+y)%2 == 0
+		}
+	}
+
+	bitmaps := map[string][][]bool{"code": q.markedBitmap(), "checker": checker}
+
+	// Random bitmaps, dark out to their edges.
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		bitmap := make([][]bool, 2+r.Intn(12))
+		for y := range bitmap {
+			bitmap[y] = make([]bool, len(bitmap))
+			for x := range bitmap[y] {
+				bitmap[y][x] = r.Intn(2) == 0
+			}
+		}
+
+		bitmaps[fmt.Sprintf("random %d", i)] = bitmap
+	}
+
+	for name, bitmap := range bitmaps {
+		const m, base, top = 0.5, 2.0, 3.0
+
+		triangles := stlMesh(bitmap, m, base, top)
+
+		// Every edge is used once in each direction.
+		type edge [2][3]float64
+
+		edges := make(map[edge]int)
+
+		volume := 0.0
+
+		for _, tr := range triangles {
+			for k := range tr {
+				edges[edge{tr[k], tr[(k+1)%3]}]++
+			}
+
+			if tr.normal() == ([3]float64{}) {
+				t.Fatalf("%s: triangle %v has no area", name, tr)
+			}
+
+			a, b, c := tr[0], tr[1], tr[2]
+			volume += (a[0]*(b[1]*c[2]-b[2]*c[1]) - a[1]*(b[0]*c[2]-b[2]*c[0]) + a[2]*(b[0]*c[1]-b[1]*c[0])) / 6
+		}
+
+		for e, count := range edges {
+			if count != 1 || edges[edge{e[1], e[0]}] != 1 {
+				t.Fatalf("%s: edge %v used %d times, reversed %d times", name, e, count, edges[edge{e[1], e[0]}])
+			}
+		}
+
+		// Regions are merged: a handful of triangles per corner of the
+		// outlines of the dark modules, and the edges of the plate.
+		corners := 0
+		for _, c := range contours(bitmap) {
+			corners += len(c)
+		}
+
+		if most := 4*corners + 12*len(bitmap); len(triangles) > most {
+			t.Errorf("%s: %d triangles, want at most %d", name, len(triangles), most)
+		}
+
+		n := float64(len(bitmap))
+		want := n * n * m * m * base
+
+		for _, row := range bitmap {
+			for _, dark := range row {
+				if dark {
+					want += m * m * (top - base)
+				}
+			}
+		}
+
+		// Each corner pulled apart takes a sliver, at most the width of the
+		// plate long, off the raised region on either side of both its
+		// outlines.
+		least := want
+
+		for y := 1; y < len(bitmap); y++ {
+			for x := 1; x < len(bitmap); x++ {
+				a, b, c, d := bitmap[y-1][x-1], bitmap[y-1][x], bitmap[y][x-1], bitmap[y][x]
+				if a == d && b == c && a != b {
+					least -= 2 * stlPinch * n * m * m * (top - base)
+				}
+			}
+		}
+
+		if volume > want+1e-9 || volume < least-1e-9 {
+			t.Errorf("%s: volume %g, want between %g and %g", name, volume, least, want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	svgo "github.com/ajstarks/svgo"
+)
+
+// SVGMode selects how SVG output is structured.
+type SVGMode int
+
+const (
+	// SVGRects draws every dark module as its own rect element.
+	SVGRects SVGMode = iota
+	// SVGPath traces the dark modules into a single path, drawn in module
+	// units and scaled to size pixels with a viewBox.
+	SVGPath
+)
+
+func (q *QRCode) SVG(size int) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+
+	var err error
+
+	switch q.SVGMode {
+	case SVGPath:
+		err = q.svgPath(&b, size)
+	default:
+		err = q.svgRects(&b, size)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	bts := b.Bytes()
+
+	if q.Base64 {
+		bts = []byte(fmt.Sprintf("data:image/svg+xml;base64,%s", base64.StdEncoding.EncodeToString(bts)))
+	}
+
+	return bts, nil
+}
+
+func (q *QRCode) svgRects(w io.Writer, size int) error {
+	bgStyle := svgStyle(q.backgroundColor())
+	fgStyle := svgStyle(q.moduleColor())
+
+	scale := math.Floor(float64(size)/float64(q.symbol.size)) + float64(1)
+	size = int(scale) * q.symbol.size
+
+	fl, err := q.frame(float64(q.symbol.size))
+	if err != nil {
+		return err
+	}
+
+	svg := svgo.New(w)
+
+	if q.Frame.isSet() {
+		// The frame extends the canvas past the origin, kept at the symbol.
+		x, y := fl.left*scale, fl.top*scale
+		width, height := float64(size)+2*x, float64(size)+y+fl.bottom*scale
+
+		svg.Start(int(math.Round(width)), int(math.Round(height)), svgViewBox(-x, -y, width, height))
+
+		svg.Scale(scale)
+		svgFills(svg, fl.fills)
+		svg.Gend()
+	} else {
+		svg.Start(size, size)
+	}
+
+	if err := q.svgGradient(svg); err != nil {
+		return err
+	}
+
+	// Layers without a colour of their own inherit the gradient.
+	if q.gradient(layer{}) != nil {
+		fgStyle = fmt.Sprintf("fill: url(#%s)", gradientID)
+	}
+
+	if !isTransparent(q.backgroundColor()) {
+		svg.Rect(0, 0, size, size, bgStyle)
+	}
+
+	svg.Group(fgStyle)
+	svg.Scale(scale)
+
+	for _, ly := range q.layers() {
+		if ly.color != nil {
+			svg.Group(svgStyle(ly.color))
+		}
+
+		if ly.modules != nil && isSquare(ly.shape) {
+			for y := 0; y < q.symbol.size; y++ {
+				for x := 0; x < q.symbol.size; x++ {
+					v := ly.modules[y][x]
+
+					if v {
+						svg.Rect(x, y, 1, 1)
+					}
+				}
+			}
+		} else if ly.modules != nil {
+			for _, p := range modulePaths(ly.modules, ly.shape) {
+				svg.Path(p.svgData(0, 0))
+			}
+		}
+
+		for _, p := range ly.paths {
+			svg.Path(p.svgData(0, 0))
+		}
+
+		if ly.color != nil {
+			svg.Gend()
+		}
+	}
+
+	if err := q.svgLogo(svg); err != nil {
+		return err
+	}
+
+	svg.Gend()
+	svg.Gend()
+	svg.End()
+
+	return nil
+}
+
+// svgStyle returns a fill style for c.
+func svgStyle(c color.Color) string {
+	// SVG expects colours that aren't premultiplied by their opacity.
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	return fmt.Sprintf("fill: rgb(%d, %d, %d); fill-opacity: %.2f",
+		n.R, n.G, n.B, float64(n.A)/255,
+	)
+}
+
+func (q *QRCode) svgPath(w io.Writer, size int) error {
+	l := q.rasterSize(size)
+	n := q.symbol.size
+
+	fl, err := q.frame(float64(n))
+	if err != nil {
+		return err
+	}
+
+	svg := svgo.New(w)
+
+	attrs := []string{fmt.Sprintf(`viewBox="0 0 %d %d"`, n, n)}
+	width, height := l.size, l.size
+
+	if q.Frame.isSet() {
+		// Pixels per module are kept, the frame extending the canvas past the
+		// origin.
+		w, h := float64(n)+2*fl.left, float64(n)+fl.top+fl.bottom
+
+		attrs[0] = svgViewBox(-fl.left, -fl.top, w, h)
+		width = int(math.Round(float64(l.size) * w / float64(n)))
+		height = int(math.Round(float64(l.size) * h / float64(n)))
+	}
+
+	// Anti-aliasing would show seams between adjacent square modules, but is
+	// needed for curved shapes.
+	square := isSquare(q.Shape) && q.FinderStyle.Shape == PatternSquare &&
+		q.EyeStyle.Shape == PatternSquare && q.AlignmentStyle.Shape == PatternSquare
+
+	if square {
+		attrs = append(attrs, `shape-rendering="crispEdges"`)
+	}
+
+	svg.Start(width, height, attrs...)
+
+	svgFills(svg, fl.fills)
+
+	if err := q.svgGradient(svg); err != nil {
+		return err
+	}
+
+	if !isTransparent(q.backgroundColor()) {
+		svg.Rect(0, 0, n, n, svgFill(q.backgroundColor()))
+	}
+
+	layers := q.layers()
+
+	// Consecutive layers of the same colour share a path.
+	var d strings.Builder
+
+	for i, ly := range layers {
+		if ly.modules != nil {
+			d.WriteString(svgShapes(ly.modules, ly.shape))
+		}
+
+		for _, p := range ly.paths {
+			d.WriteString(p.svgData(0, 0))
+		}
+
+		fill := q.svgLayerFill(ly)
+
+		if i+1 < len(layers) && q.svgLayerFill(layers[i+1]) == fill {
+			continue
+		}
+
+		if d.Len() > 0 {
+			svg.Path(d.String(), fill)
+		}
+
+		d.Reset()
+	}
+
+	if err := q.svgLogo(svg); err != nil {
+		return err
+	}
+
+	svg.End()
+
+	return nil
+}
+
+// svgViewBox returns a viewBox attribute.
+func svgViewBox(x float64, y float64, w float64, h float64) string {
+	return fmt.Sprintf(`viewBox="%s %s %s %s"`, svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h))
+}
+
+// svgFills draws fills in module coordinates.
+func svgFills(svg *svgo.SVG, fills []pathFill) {
+	for _, p := range fills {
+		svg.Path(p.path.svgData(0, 0), svgFill(p.color))
+	}
+}
+
+// svgGradient defines the foreground gradient, if any, in module coordinates.
+func (q *QRCode) svgGradient(svg *svgo.SVG) error {
+	g := q.ForegroundGradient
+	if g == nil || len(g.Stops) == 0 {
+		return nil
+	}
+
+	return g.svgGradient(svg.Writer, float64(q.symbol.quietZoneSize), float64(q.symbol.symbolSize))
+}
+
+// svgLayerFill returns fill attributes for ly.
+func (q *QRCode) svgLayerFill(ly layer) string {
+	if q.gradient(ly) != nil {
+		return fmt.Sprintf(`fill="url(#%s)"`, gradientID)
+	}
+
+	return svgFill(ly.paint(q))
+}
+
+// svgLogo embeds the logo, if any, as a PNG image in module coordinates.
+func (q *QRCode) svgLogo(svg *svgo.SVG) error {
+	if q.Logo == nil {
+		return nil
+	}
+
+	uri, err := q.logoDataURI()
+	if err != nil {
+		return err
+	}
+
+	x, y, w, h := q.logoBox(1, 0, 0)
+
+	_, err = fmt.Fprintf(svg.Writer, `<image x="%s" y="%s" width="%s" height="%s" xlink:href="%s" />`+"\n",
+		svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), uri)
+	if err != nil {
+		return err
+	}
+
+	svgFills(svg, q.logoOverlay())
+
+	return nil
+}
+
+// svgShapes returns path data for the set modules of bitmap drawn with shape.
+func svgShapes(bitmap [][]bool, shape Shape) string {
+	if isSquare(shape) {
+		return svgOutlines(contours(bitmap))
+	}
+
+	var d strings.Builder
+
+	for _, p := range modulePaths(bitmap, shape) {
+		d.WriteString(p.svgData(0, 0))
+	}
+
+	return d.String()
+}
+
+// svgOutlines returns path data for outlines made of horizontal and vertical
+// lines only.
+func svgOutlines(outlines [][]modulePoint) string {
+	var d strings.Builder
+
+	for _, o := range outlines {
+		fmt.Fprintf(&d, "M%d %d", o[0].x, o[0].y)
+
+		for i := 1; i < len(o); i++ {
+			if o[i].y == o[i-1].y {
+				fmt.Fprintf(&d, "H%d", o[i].x)
+			} else {
+				fmt.Fprintf(&d, "V%d", o[i].y)
+			}
+		}
+
+		d.WriteString("Z")
+	}
+
+	return d.String()
+}
+
+// svgFill returns fill attributes for c, omitting the opacity when opaque.
+func svgFill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+
+	if n.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.2f"`, float64(n.A)/0xff)
+	}
+
+	return fill
+}
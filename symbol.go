@@ -16,17 +16,46 @@ type symbol struct {
 
 	// Width/height of a single quiet zone.
 	quietZoneSize int
+
+	// Function pattern the module at [y][x] belongs to, if any.
+	patterns [][]modulePattern
+
+	// Index in the interleaved codeword stream of the module at [y][x], or -1
+	// for function patterns, remainder bits and the quiet zone.
+	codewords [][]int
+
+	// Top left corners of the finder and alignment patterns, quiet zone
+	// included. Finder patterns are top left, top right and bottom left.
+	finders    []modulePoint
+	alignments []modulePoint
 }
 
+type modulePattern uint8
+
+const (
+	patternNone modulePattern = iota
+	patternFinder
+	patternFinderEye
+	patternAlignment
+)
+
 func newSymbol(size int, quietZoneSize int) *symbol {
 	var m symbol
 
 	m.module = make([][]bool, size+2*quietZoneSize)
 	m.isUsed = make([][]bool, size+2*quietZoneSize)
+	m.patterns = make([][]modulePattern, size+2*quietZoneSize)
+	m.codewords = make([][]int, size+2*quietZoneSize)
 
 	for i := range m.module {
 		m.module[i] = make([]bool, size+2*quietZoneSize)
 		m.isUsed[i] = make([]bool, size+2*quietZoneSize)
+		m.patterns[i] = make([]modulePattern, size+2*quietZoneSize)
+		m.codewords[i] = make([]int, size+2*quietZoneSize)
+
+		for j := range m.codewords[i] {
+			m.codewords[i][j] = -1
+		}
 	}
 
 	m.size = size + 2*quietZoneSize
@@ -72,6 +101,20 @@ func (m *symbol) set2dPattern(x int, y int, v [][]bool) {
 	}
 }
 
+// markPattern records the w x h modules from (x, y) as part of pattern p.
+func (m *symbol) markPattern(x int, y int, w int, h int, p modulePattern) {
+	for j := y; j < y+h; j++ {
+		for i := x; i < x+w; i++ {
+			m.patterns[j+m.quietZoneSize][i+m.quietZoneSize] = p
+		}
+	}
+}
+
+// pattern returns the function pattern of the module at [y][x] of the bitmap.
+func (m *symbol) pattern(x int, y int) modulePattern {
+	return m.patterns[y][x]
+}
+
 func (m *symbol) bitmap() [][]bool {
 	module := make([][]bool, len(m.module))
 
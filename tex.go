@@ -0,0 +1,123 @@
+package qrcode
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// TeXMode selects the LaTeX drawing package used by TeX output.
+type TeXMode int
+
+const (
+	// TeXTikZ draws with a tikzpicture environment, needing the tikz package.
+	TeXTikZ TeXMode = iota
+	// TeXPicture draws with the picture environment of LaTeX itself, needing
+	// only the xcolor package for colours.
+	TeXPicture
+	// TeXPSTricks draws with a pspicture environment, needing the pstricks
+	// package.
+	TeXPSTricks
+)
+
+// TeXOptions style TeX output.
+type TeXOptions struct {
+	// LaTeX drawing package used.
+	Mode TeXMode
+}
+
+// TeX returns LaTeX source drawing the QR Code width wide, quiet zone
+// included, with dark modules merged into rectangles. It defines the colours
+// qrcodefg and qrcodebg, and is meant to be included with \input. Width and
+// ModuleSize override width when set.
+func (q *QRCode) TeX(width Length, opts TeXOptions) ([]byte, error) {
+	if err := q.encode(); err != nil {
+		return nil, err
+	}
+
+	n := q.symbol.size
+
+	width, err := q.vectorWidth(width)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lengths are given in big points, the points of Length.
+	unit := svgNumber(width.Points() / float64(n))
+
+	var b strings.Builder
+
+	// TeX colours are opaque, so translucent modules are blended with the
+	// background.
+	fg := q.moduleColor()
+	if _, ok := fg.(color.CMYK); !ok {
+		fg = q.over(fg)
+	}
+
+	fmt.Fprintf(&b, "%s\n", texColor("qrcodefg", fg))
+
+	bg := q.backgroundColor()
+	if !isTransparent(bg) {
+		fmt.Fprintf(&b, "%s\n", texColor("qrcodebg", bg))
+	}
+
+	rects := rectangles(q.symbol.bitmap())
+
+	switch opts.Mode {
+	case TeXPicture:
+		// The picture environment has y pointing up and draws filled boxes as
+		// rules.
+		fmt.Fprintf(&b, "{\\setlength{\\unitlength}{%sbp}%%\n\\begin{picture}(%d,%d)\n", unit, n, n)
+
+		if !isTransparent(bg) {
+			fmt.Fprintf(&b, "\\put(0,0){\\color{qrcodebg}\\rule{%d\\unitlength}{%d\\unitlength}}\n", n, n)
+		}
+
+		for _, r := range rects {
+			fmt.Fprintf(&b, "\\put(%d,%d){\\color{qrcodefg}\\rule{%d\\unitlength}{%d\\unitlength}}\n",
+				r.x, n-r.y-r.h, r.w, r.h)
+		}
+
+		b.WriteString("\\end{picture}}\n")
+	case TeXPSTricks:
+		fmt.Fprintf(&b, "{\\psset{unit=%sbp}%%\n\\begin{pspicture}(0,0)(%d,%d)\n", unit, n, n)
+
+		if !isTransparent(bg) {
+			fmt.Fprintf(&b, "\\psframe*[linecolor=qrcodebg](0,0)(%d,%d)\n", n, n)
+		}
+
+		for _, r := range rects {
+			fmt.Fprintf(&b, "\\psframe*[linecolor=qrcodefg](%d,%d)(%d,%d)\n", r.x, n-r.y-r.h, r.x+r.w, n-r.y)
+		}
+
+		b.WriteString("\\end{pspicture}}\n")
+	default:
+		// A negative y unit keeps the rows in bitmap order.
+		fmt.Fprintf(&b, "\\begin{tikzpicture}[x=%sbp,y=-%sbp]\n", unit, unit)
+
+		if !isTransparent(bg) {
+			fmt.Fprintf(&b, "\\fill[qrcodebg] (0,0) rectangle (%d,%d);\n", n, n)
+		}
+
+		for _, r := range rects {
+			fmt.Fprintf(&b, "\\fill[qrcodefg] (%d,%d) rectangle ++(%d,%d);\n", r.x, r.y, r.w, r.h)
+		}
+
+		b.WriteString("\\end{tikzpicture}\n")
+	}
+
+	return []byte(b.String()), nil
+}
+
+// texColor returns an xcolor definition of name as c, kept as CMYK for
+// color.CMYK values. The alpha channel is dropped.
+func texColor(name string, c color.Color) string {
+	if c, ok := c.(color.CMYK); ok {
+		return fmt.Sprintf("\\definecolor{%s}{cmyk}{%s,%s,%s,%s}",
+			name, epsComponent(c.C), epsComponent(c.M), epsComponent(c.Y), epsComponent(c.K))
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	return fmt.Sprintf("\\definecolor{%s}{RGB}{%d,%d,%d}", name, n.R, n.G, n.B)
+}
@@ -0,0 +1,193 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// TextMode selects the characters used by text output.
+type TextMode int
+
+const (
+	// TextHalfBlock packs two module rows into each line with the Unicode
+	// upper and lower half block characters.
+	TextHalfBlock TextMode = iota
+	// TextFullBlock draws each module as two full block characters, one line
+	// per module row, for fonts that render half blocks unevenly.
+	TextFullBlock
+	// TextASCII draws each module as two '#' characters, for terminals
+	// without Unicode support.
+	TextASCII
+)
+
+// TextOptions style text output.
+type TextOptions struct {
+	Mode TextMode
+
+	// Color paints modules with ANSI escape sequences in their colours.
+	// Otherwise characters mark the dark modules, or the light ones with
+	// Invert for terminals with light text on a dark background.
+	Color  bool
+	Invert bool
+}
+
+// Text returns the QR Code as text for display in a terminal.
+func (q *QRCode) Text(opts TextOptions) (string, error) {
+	var b strings.Builder
+
+	if err := q.WriteText(&b, opts); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// WriteText writes the QR Code as text for display in a terminal, one line
+// per row of characters. The quiet zone is Margin modules wide.
+func (q *QRCode) WriteText(w io.Writer, opts TextOptions) error {
+	if err := q.encode(); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	t := textWriter{q: q, opts: opts, w: bw}
+
+	bitmap := q.symbol.bitmap()
+
+	if opts.Mode == TextHalfBlock {
+		for y := 0; y < len(bitmap); y += 2 {
+			// An odd last row is paired with a light one.
+			bottom := make([]bool, len(bitmap[y]))
+			if y+1 < len(bitmap) {
+				bottom = bitmap[y+1]
+			}
+
+			for x := range bitmap[y] {
+				t.halfBlock(bitmap[y][x], bottom[x])
+			}
+
+			t.endLine()
+		}
+	} else {
+		for y := range bitmap {
+			for x := range bitmap[y] {
+				t.block(bitmap[y][x])
+			}
+
+			t.endLine()
+		}
+	}
+
+	return bw.Flush()
+}
+
+// textWriter writes the characters of text output, tracking the current ANSI
+// colours so escape sequences are only written when they change.
+type textWriter struct {
+	q    *QRCode
+	opts TextOptions
+	w    *bufio.Writer
+
+	fg, bg string
+}
+
+// halfBlock writes the character for a module above another.
+func (t *textWriter) halfBlock(top bool, bottom bool) {
+	if t.opts.Color {
+		// The upper half takes the text colour and the lower half the
+		// background, whatever the modules.
+		t.colors(t.ansi(top, 38), t.ansi(bottom, 48))
+		t.write("▀")
+
+		return
+	}
+
+	// Blocks mark light modules when inverted.
+	if t.opts.Invert {
+		top, bottom = !top, !bottom
+	}
+
+	switch {
+	case top && bottom:
+		t.write("█")
+	case top:
+		t.write("▀")
+	case bottom:
+		t.write("▄")
+	default:
+		t.write(" ")
+	}
+}
+
+// block writes the two characters for a module.
+func (t *textWriter) block(dark bool) {
+	if t.opts.Color {
+		t.colors(t.fg, t.ansi(dark, 48))
+		t.write("  ")
+
+		return
+	}
+
+	if t.opts.Invert {
+		dark = !dark
+	}
+
+	switch {
+	case !dark:
+		t.write("  ")
+	case t.opts.Mode == TextASCII:
+		t.write("##")
+	default:
+		t.write("██")
+	}
+}
+
+// endLine resets the colours and ends the line.
+func (t *textWriter) endLine() {
+	if t.fg != "" || t.bg != "" {
+		t.write("\x1b[0m")
+		t.fg, t.bg = "", ""
+	}
+
+	t.write("\n")
+}
+
+// colors switches to the fg and bg escape sequences, if not current.
+func (t *textWriter) colors(fg string, bg string) {
+	if fg != t.fg {
+		t.write(fg)
+		t.fg = fg
+	}
+
+	if bg != t.bg {
+		t.write(bg)
+		t.bg = bg
+	}
+}
+
+// ansi returns the escape sequence setting the text (38) or background (48)
+// colour to that of a dark or light module. A transparent colour selects the
+// terminal default.
+func (t *textWriter) ansi(dark bool, code int) string {
+	c := t.q.backgroundColor()
+	if dark {
+		c = t.q.over(t.q.moduleColor())
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	if n.A == 0 {
+		return fmt.Sprintf("\x1b[%dm", code+1)
+	}
+
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", code, n.R, n.G, n.B)
+}
+
+// write writes s. A bufio.Writer keeps its first error and returns it from
+// Flush, which WriteText checks, so the result is deliberately ignored here.
+func (t *textWriter) write(s string) {
+	_, _ = t.w.WriteString(s)
+}
@@ -0,0 +1,112 @@
+package qrcode
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// TIFF tags written, in the increasing order TIFF requires.
+const (
+	tiffImageWidth                = 256
+	tiffImageLength               = 257
+	tiffBitsPerSample             = 258
+	tiffCompression               = 259
+	tiffPhotometricInterpretation = 262
+	tiffStripOffsets              = 273
+	tiffRowsPerStrip              = 278
+	tiffStripByteCounts           = 279
+	tiffXResolution               = 282
+	tiffYResolution               = 283
+	tiffResolutionUnit            = 296
+)
+
+// TIFF field types.
+const (
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// TIFF returns the QR Code as a 1-bit TIFF of size x size pixels compressed
+// with CCITT Group 4, with dark modules in black whatever the colours.
+func (q *QRCode) TIFF(size int) ([]byte, error) {
+	img, err := q.bilevel(size)
+	if err != nil {
+		return nil, err
+	}
+
+	n := img.Bounds().Dx()
+
+	rows := make([][]bool, n)
+
+	for y := range rows {
+		rows[y] = make([]bool, n)
+
+		for x := range rows[y] {
+			rows[y][x] = q.isBlack(img, x, y)
+		}
+	}
+
+	data := g4Encode(rows)
+
+	type entry struct {
+		tag, typ uint16
+		value    uint32
+	}
+
+	const (
+		headerSize = 8
+		numEntries = 11
+		ifdSize    = 2 + numEntries*12 + 4
+	)
+
+	// The resolution rationals follow the IFD, then the image data.
+	resolution := uint32(headerSize + ifdSize)
+	dataOffset := resolution + 8
+
+	entries := [numEntries]entry{
+		{tiffImageWidth, tiffLong, uint32(n)},
+		{tiffImageLength, tiffLong, uint32(n)},
+		{tiffBitsPerSample, tiffShort, 1},
+		{tiffCompression, tiffShort, 4},               // CCITT Group 4.
+		{tiffPhotometricInterpretation, tiffShort, 0}, // White is zero.
+		{tiffStripOffsets, tiffLong, dataOffset},
+		{tiffRowsPerStrip, tiffLong, uint32(n)},
+		{tiffStripByteCounts, tiffLong, uint32(len(data))},
+		{tiffXResolution, tiffRational, resolution},
+		{tiffYResolution, tiffRational, resolution},
+		{tiffResolutionUnit, tiffShort, 2}, // Inch.
+	}
+
+	le := binary.LittleEndian
+
+	header := make([]byte, dataOffset)
+
+	copy(header, "II")
+	le.PutUint16(header[2:], 42)
+	le.PutUint32(header[4:], headerSize)
+
+	le.PutUint16(header[headerSize:], numEntries)
+
+	for i, e := range entries {
+		p := header[headerSize+2+12*i:]
+
+		le.PutUint16(p[0:], e.tag)
+		le.PutUint16(p[2:], e.typ)
+		le.PutUint32(p[4:], 1)
+
+		// The value fits in the entry, left justified.
+		if e.typ == tiffShort {
+			le.PutUint16(p[8:], uint16(e.value))
+		} else {
+			le.PutUint32(p[8:], e.value)
+		}
+	}
+
+	// The offset of the next IFD stays zero as there is none. Resolution is
+	// given in hundredths of dots per inch.
+	le.PutUint32(header[resolution:], uint32(math.Round(q.dpi()*100)))
+	le.PutUint32(header[resolution+4:], 100)
+
+	return q.base64(append(header, data...), "image/tiff"), nil
+}
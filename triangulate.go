@@ -0,0 +1,614 @@
+package qrcode
+
+import (
+	"math"
+	"sort"
+)
+
+// earNode is a vertex of a polygon being cut into triangles, linked to its
+// neighbours along the outline.
+type earNode struct {
+	// Index of the vertex, shared by the copies made when splitting.
+	i int
+
+	x, y float64
+
+	// Position along the z-order curve.
+	z uint32
+
+	prev, next   *earNode
+	prevZ, nextZ *earNode
+}
+
+// earcut holds the triangles cut so far and the scale of the z-order curve,
+// which keeps vertices near each other in the plane near each other in
+// order, so that only the vertices near an ear need to be checked.
+type earcut struct {
+	triangles [][3]int
+
+	minX, minY, scale float64
+}
+
+// triangulate cuts a polygon into triangles by ear clipping, following the
+// earcut algorithm. The first ring is the outline and the others are holes,
+// and triangles are returned anticlockwise as indices into the rings'
+// vertices taken in order. Holes are first joined to the outline by bridges.
+func triangulate(rings [][][2]float64) [][3]int {
+	if len(rings) == 0 {
+		return nil
+	}
+
+	e := &earcut{minX: math.Inf(1), minY: math.Inf(1)}
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	for _, ring := range rings {
+		for _, p := range ring {
+			e.minX, e.minY = math.Min(e.minX, p[0]), math.Min(e.minY, p[1])
+			maxX, maxY = math.Max(maxX, p[0]), math.Max(maxY, p[1])
+		}
+	}
+
+	if size := math.Max(maxX-e.minX, maxY-e.minY); size > 0 {
+		e.scale = 32767 / size
+	}
+
+	outer := earRing(rings[0], 0, true)
+	if outer == nil || outer.next == outer.prev {
+		return nil
+	}
+
+	e.order(outer)
+
+	offset := len(rings[0])
+
+	var holes []*earNode
+
+	for _, ring := range rings[1:] {
+		if h := earRing(ring, offset, false); h != nil {
+			e.order(h)
+			holes = append(holes, earLeftmost(h))
+		}
+
+		offset += len(ring)
+	}
+
+	sort.Slice(holes, func(i, j int) bool {
+		return holes[i].x < holes[j].x
+	})
+
+	for _, h := range holes {
+		outer = earBridgeHole(h, outer)
+	}
+
+	e.clip(outer, 0)
+
+	return e.triangles
+}
+
+// order sets the z-order of the vertices of the polygon at start.
+func (e *earcut) order(start *earNode) {
+	for p := start; ; {
+		p.z = e.z(p.x, p.y)
+
+		if p = p.next; p == start {
+			return
+		}
+	}
+}
+
+// z returns the position of (x, y) along the z-order curve, interleaving
+// the bits of its scaled coordinates.
+func (e *earcut) z(x float64, y float64) uint32 {
+	spread := func(v uint32) uint32 {
+		v = (v | v<<8) & 0x00FF00FF
+		v = (v | v<<4) & 0x0F0F0F0F
+		v = (v | v<<2) & 0x33333333
+
+		return (v | v<<1) & 0x55555555
+	}
+
+	return spread(uint32((x-e.minX)*e.scale)) | spread(uint32((y-e.minY)*e.scale))<<1
+}
+
+// earIndex links the vertices of the polygon at start in z-order.
+func earIndex(start *earNode) {
+	var nodes []*earNode
+
+	for p := start; ; {
+		nodes = append(nodes, p)
+
+		if p = p.next; p == start {
+			break
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].z < nodes[j].z
+	})
+
+	for k, p := range nodes {
+		p.prevZ, p.nextZ = nil, nil
+
+		if k > 0 {
+			p.prevZ = nodes[k-1]
+		}
+
+		if k+1 < len(nodes) {
+			p.nextZ = nodes[k+1]
+		}
+	}
+}
+
+// earRing links the vertices of ring, numbered from offset, anticlockwise if
+// ccw is set and clockwise otherwise.
+func earRing(ring [][2]float64, offset int, ccw bool) *earNode {
+	area := 0.0
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		area += (ring[j][0] - ring[i][0]) * (ring[i][1] + ring[j][1])
+	}
+
+	var last *earNode
+
+	if ccw == (area > 0) {
+		for i, p := range ring {
+			last = earInsert(offset+i, p, last)
+		}
+	} else {
+		for i := len(ring) - 1; i >= 0; i-- {
+			last = earInsert(offset+i, ring[i], last)
+		}
+	}
+
+	if last != nil && last.equals(last.next) {
+		earRemove(last)
+		last = last.next
+	}
+
+	return last
+}
+
+// clip cuts ears off the polygon at ear. When none is left, it retries
+// after removing degenerate vertices, then after curing local
+// self-intersections, and finally by splitting the polygon in two.
+func (e *earcut) clip(ear *earNode, pass int) {
+	if ear == nil {
+		return
+	}
+
+	if pass == 0 {
+		earIndex(ear)
+	}
+
+	stop := ear
+
+	for ear.prev != ear.next {
+		prev, next := ear.prev, ear.next
+
+		if e.isEar(ear) {
+			e.triangles = append(e.triangles, [3]int{prev.i, ear.i, next.i})
+
+			earRemove(ear)
+
+			ear, stop = next.next, next.next
+
+			continue
+		}
+
+		ear = next
+
+		if ear != stop {
+			continue
+		}
+
+		switch pass {
+		case 0:
+			e.clip(earFilter(ear, nil), 1)
+		case 1:
+			e.clip(e.cure(earFilter(ear, nil)), 2)
+		default:
+			e.split(ear)
+		}
+
+		return
+	}
+}
+
+// isEar reports whether the triangle of n and its neighbours can be cut off:
+// n is convex and no other vertex lies inside the triangle.
+// Only the vertices within the triangle's bounds along the z-order curve are
+// checked.
+func (e *earcut) isEar(n *earNode) bool {
+	a, b, c := n.prev, n, n.next
+
+	if earArea(a, b, c) >= 0 {
+		return false
+	}
+
+	lo := e.z(math.Min(a.x, math.Min(b.x, c.x)), math.Min(a.y, math.Min(b.y, c.y)))
+	hi := e.z(math.Max(a.x, math.Max(b.x, c.x)), math.Max(a.y, math.Max(b.y, c.y)))
+
+	inside := func(p *earNode) bool {
+		return p != a && p != c && earInTriangle(a.x, a.y, b.x, b.y, c.x, c.y, p.x, p.y) && earArea(p.prev, p, p.next) >= 0
+	}
+
+	for p := n.prevZ; p != nil && p.z >= lo; p = p.prevZ {
+		if inside(p) {
+			return false
+		}
+	}
+
+	for p := n.nextZ; p != nil && p.z <= hi; p = p.nextZ {
+		if inside(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// earFilter removes duplicate vertices and vertices doubling back between
+// start and end. Vertices along a straight edge are kept, as the triangles
+// must meet the neighbouring faces there.
+func earFilter(start *earNode, end *earNode) *earNode {
+	if start == nil {
+		return nil
+	}
+
+	if end == nil {
+		end = start
+	}
+
+	p := start
+
+	for {
+		again := false
+
+		if p.equals(p.next) || earArea(p.prev, p, p.next) == 0 && !p.between() {
+			earRemove(p)
+
+			p, end = p.prev, p.prev
+			if p == p.next {
+				break
+			}
+
+			again = true
+		} else {
+			p = p.next
+		}
+
+		if !again && p == end {
+			break
+		}
+	}
+
+	return end
+}
+
+// cure cuts off the triangles of local self-intersections, where two
+// edges a vertex apart cross.
+func (e *earcut) cure(start *earNode) *earNode {
+	p := start
+
+	for {
+		a, b := p.prev, p.next.next
+
+		if !a.equals(b) && earIntersects(a, p, p.next, b) && earLocallyInside(a, b) && earLocallyInside(b, a) {
+			e.triangles = append(e.triangles, [3]int{a.i, p.i, b.i})
+
+			earRemove(p)
+			earRemove(p.next)
+
+			p, start = b, b
+		}
+
+		p = p.next
+
+		if p == start {
+			break
+		}
+	}
+
+	return earFilter(p, nil)
+}
+
+// split looks for a diagonal splitting the polygon in two and clips both
+// halves.
+func (e *earcut) split(start *earNode) {
+	a := start
+
+	for {
+		for b := a.next.next; b != a.prev; b = b.next {
+			if a.i != b.i && earValidDiagonal(a, b) {
+				c := earSplitPolygon(a, b)
+
+				a = earFilter(a, a.next)
+				c = earFilter(c, c.next)
+
+				e.clip(a, 0)
+				e.clip(c, 0)
+
+				return
+			}
+		}
+
+		a = a.next
+
+		if a == start {
+			return
+		}
+	}
+}
+
+// earBridgeHole joins hole to the polygon at outer with a bridge from its
+// leftmost vertex.
+func earBridgeHole(hole *earNode, outer *earNode) *earNode {
+	bridge := earFindBridge(hole, outer)
+	if bridge == nil {
+		return outer
+	}
+
+	reverse := earSplitPolygon(bridge, hole)
+	earFilter(reverse, reverse.next)
+
+	return earFilter(bridge, bridge.next)
+}
+
+// earFindBridge finds a vertex of the polygon at outer visible from the
+// leftmost vertex of a hole.
+func earFindBridge(hole *earNode, outer *earNode) *earNode {
+	hx, hy := hole.x, hole.y
+	qx := math.Inf(-1)
+
+	var m *earNode
+
+	// The nearest edge to the left of the hole crossing its horizontal, and
+	// the endpoint of that edge further left.
+	p := outer
+
+	for {
+		if hy <= p.y && hy >= p.next.y && p.next.y != p.y {
+			x := p.x + (hy-p.y)*(p.next.x-p.x)/(p.next.y-p.y)
+
+			if x <= hx && x > qx {
+				qx = x
+
+				m = p.next
+				if p.x < p.next.x {
+					m = p
+				}
+
+				if x == hx {
+					// The hole touches the edge.
+					return m
+				}
+			}
+		}
+
+		p = p.next
+
+		if p == outer {
+			break
+		}
+	}
+
+	if m == nil {
+		return nil
+	}
+
+	// Vertices inside the triangle of the hole vertex, the crossing and m
+	// may hide m: bridge to the one at the smallest angle instead.
+	stop := m
+	mx, my := m.x, m.y
+	tanMin := math.Inf(1)
+
+	p = m
+
+	for {
+		ax, cx := qx, hx
+		if hy < my {
+			ax, cx = hx, qx
+		}
+
+		if hx >= p.x && p.x >= mx && hx != p.x && earInTriangle(ax, hy, mx, my, cx, hy, p.x, p.y) {
+			tan := (hy - p.y) / (hx - p.x)
+			if tan < 0 {
+				tan = -tan
+			}
+
+			if earLocallyInside(p, hole) && (tan < tanMin || tan == tanMin && (p.x > m.x || p.x == m.x && earSectorContainsSector(m, p))) {
+				m = p
+				tanMin = tan
+			}
+		}
+
+		p = p.next
+
+		if p == stop {
+			break
+		}
+	}
+
+	return m
+}
+
+// earSectorContainsSector reports whether the sector of the polygon at m
+// contains the sector at p.
+func earSectorContainsSector(m *earNode, p *earNode) bool {
+	return earArea(m.prev, m, p.prev) < 0 && earArea(p.next, m, m.next) < 0
+}
+
+// earLeftmost returns the leftmost vertex of the ring at start, the lowest of
+// those.
+func earLeftmost(start *earNode) *earNode {
+	leftmost := start
+
+	for p := start.next; p != start; p = p.next {
+		if p.x < leftmost.x || p.x == leftmost.x && p.y < leftmost.y {
+			leftmost = p
+		}
+	}
+
+	return leftmost
+}
+
+// earInTriangle reports whether (px, py) lies in the triangle a, b, c or on
+// its edges.
+func earInTriangle(ax, ay, bx, by, cx, cy, px, py float64) bool {
+	return (cx-px)*(ay-py) >= (ax-px)*(cy-py) &&
+		(ax-px)*(by-py) >= (bx-px)*(ay-py) &&
+		(bx-px)*(cy-py) >= (cx-px)*(by-py)
+}
+
+// earValidDiagonal reports whether a diagonal from a to b lies inside the
+// polygon without crossing its edges.
+func earValidDiagonal(a *earNode, b *earNode) bool {
+	if a.next.i == b.i || a.prev.i == b.i || earIntersectsPolygon(a, b) {
+		return false
+	}
+
+	if earLocallyInside(a, b) && earLocallyInside(b, a) && earMiddleInside(a, b) &&
+		(earArea(a.prev, a, b.prev) != 0 || earArea(a, b.prev, b) != 0) {
+		return true
+	}
+
+	// Zero length diagonals between copies of a vertex.
+	return a.equals(b) && earArea(a.prev, a, a.next) > 0 && earArea(b.prev, b, b.next) > 0
+}
+
+// between reports whether n lies strictly between its neighbours.
+func (n *earNode) between() bool {
+	return (n.prev.x-n.x)*(n.next.x-n.x)+(n.prev.y-n.y)*(n.next.y-n.y) < 0
+}
+
+// earArea returns twice the area of the triangle p, q, r, negative when
+// anticlockwise.
+func earArea(p *earNode, q *earNode, r *earNode) float64 {
+	return (q.y-p.y)*(r.x-q.x) - (q.x-p.x)*(r.y-q.y)
+}
+
+func (n *earNode) equals(m *earNode) bool {
+	return n.x == m.x && n.y == m.y
+}
+
+// earIntersects reports whether the segments p1 q1 and p2 q2 meet.
+func earIntersects(p1 *earNode, q1 *earNode, p2 *earNode, q2 *earNode) bool {
+	o1 := earSign(earArea(p1, q1, p2))
+	o2 := earSign(earArea(p1, q1, q2))
+	o3 := earSign(earArea(p2, q2, p1))
+	o4 := earSign(earArea(p2, q2, q1))
+
+	return o1 != o2 && o3 != o4 ||
+		o1 == 0 && earOnSegment(p1, p2, q1) ||
+		o2 == 0 && earOnSegment(p1, q2, q1) ||
+		o3 == 0 && earOnSegment(p2, p1, q2) ||
+		o4 == 0 && earOnSegment(p2, q1, q2)
+}
+
+// earOnSegment reports whether q, collinear with p and r, lies between them.
+func earOnSegment(p *earNode, q *earNode, r *earNode) bool {
+	return q.x <= math.Max(p.x, r.x) && q.x >= math.Min(p.x, r.x) && q.y <= math.Max(p.y, r.y) && q.y >= math.Min(p.y, r.y)
+}
+
+func earSign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// earIntersectsPolygon reports whether the diagonal a b crosses an edge of
+// the polygon.
+func earIntersectsPolygon(a *earNode, b *earNode) bool {
+	p := a
+
+	for {
+		if p.i != a.i && p.next.i != a.i && p.i != b.i && p.next.i != b.i && earIntersects(p, p.next, a, b) {
+			return true
+		}
+
+		p = p.next
+
+		if p == a {
+			return false
+		}
+	}
+}
+
+// earLocallyInside reports whether the diagonal a b starts inside the
+// polygon at a.
+func earLocallyInside(a *earNode, b *earNode) bool {
+	if earArea(a.prev, a, a.next) < 0 {
+		return earArea(a, b, a.next) >= 0 && earArea(a, a.prev, b) >= 0
+	}
+
+	return earArea(a, b, a.prev) < 0 || earArea(a, a.next, b) < 0
+}
+
+// earMiddleInside reports whether the middle of the diagonal a b is inside
+// the polygon.
+func earMiddleInside(a *earNode, b *earNode) bool {
+	inside := false
+	px, py := (a.x+b.x)/2, (a.y+b.y)/2
+
+	p := a
+
+	for {
+		if (p.y > py) != (p.next.y > py) && p.next.y != p.y && px < (p.next.x-p.x)*(py-p.y)/(p.next.y-p.y)+p.x {
+			inside = !inside
+		}
+
+		p = p.next
+
+		if p == a {
+			return inside
+		}
+	}
+}
+
+// earSplitPolygon links a to b with a diagonal, splitting the polygon in
+// two, and returns a vertex of the second half.
+func earSplitPolygon(a *earNode, b *earNode) *earNode {
+	a2 := &earNode{i: a.i, x: a.x, y: a.y, z: a.z}
+	b2 := &earNode{i: b.i, x: b.x, y: b.y, z: b.z}
+	an, bp := a.next, b.prev
+
+	a.next, b.prev = b, a
+	a2.next, an.prev = an, a2
+	b2.next, a2.prev = a2, b2
+	bp.next, b2.prev = b2, bp
+
+	return b2
+}
+
+// earInsert links a new vertex after last.
+func earInsert(i int, p [2]float64, last *earNode) *earNode {
+	n := &earNode{i: i, x: p[0], y: p[1]}
+
+	if last == nil {
+		n.prev, n.next = n, n
+	} else {
+		n.next, n.prev = last.next, last
+		last.next.prev = n
+		last.next = n
+	}
+
+	return n
+}
+
+func earRemove(n *earNode) {
+	n.next.prev = n.prev
+	n.prev.next = n.next
+
+	if n.prevZ != nil {
+		n.prevZ.nextZ = n.nextZ
+	}
+
+	if n.nextZ != nil {
+		n.nextZ.prevZ = n.prevZ
+	}
+}
@@ -0,0 +1,149 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Length is a physical distance, stored in points (1/72 inch).
+type Length float64
+
+const (
+	Point      Length = 1
+	Inch       Length = 72
+	Millimeter Length = Inch / 25.4
+)
+
+// Points returns the length in points.
+func (l Length) Points() float64 {
+	return float64(l)
+}
+
+// Dots returns the number of device pixels covered by the length at dpi.
+func (l Length) Dots(dpi float64) float64 {
+	return float64(l) / float64(Inch) * dpi
+}
+
+const defaultDPI float64 = 72
+
+func (q *QRCode) dpi() float64 {
+	if q.DPI <= 0 {
+		return defaultDPI
+	}
+
+	return q.DPI
+}
+
+// physicalSize overrides the renderer size argument with the physical size
+// options, if any are set. Width is the size of the whole image, so it is
+// stretched to rather than taken as pixels per module.
+func (q *QRCode) physicalSize(size int, mode ScaleMode) (int, ScaleMode) {
+	switch {
+	case q.ModuleSize > 0:
+		return int(math.Round(q.ModuleSize.Dots(q.dpi()))), ScaleModule
+	case q.Width > 0:
+		if mode == ScaleModule {
+			mode = ScaleStretch
+		}
+
+		return int(math.Round(q.Width.Dots(q.dpi()))), mode
+	default:
+		return size, mode
+	}
+}
+
+// physicalWidth returns the width of an image of pixels width in points.
+func (q *QRCode) physicalWidth(pixels int) float64 {
+	switch {
+	case q.ModuleSize > 0:
+		return q.ModuleSize.Points() * float64(q.symbol.size)
+	case q.Width > 0:
+		return q.Width.Points()
+	default:
+		return float64(pixels) / q.dpi() * Inch.Points()
+	}
+}
+
+// vectorWidth overrides the width argument of renderers taking a Length with
+// the physical size options, if any are set.
+func (q *QRCode) vectorWidth(width Length) (Length, error) {
+	switch {
+	case q.ModuleSize > 0:
+		width = q.ModuleSize * Length(q.symbol.size)
+	case q.Width > 0:
+		width = q.Width
+	}
+
+	if width <= 0 {
+		return 0, errors.New("width must be positive")
+	}
+
+	return width, nil
+}
+
+// pngWithDPI inserts a pHYs chunk after the IHDR chunk of an encoded PNG.
+func pngWithDPI(b []byte, dpi float64) []byte {
+	// Signature (8 bytes) followed by IHDR (length, type, 13 bytes data, CRC).
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+
+	if len(b) < ihdrEnd {
+		return b
+	}
+
+	var out bytes.Buffer
+
+	out.Write(b[:ihdrEnd])
+	pngChunk(&out, "pHYs", pngPhys(dpi))
+	out.Write(b[ihdrEnd:])
+
+	return out.Bytes()
+}
+
+// pngPhys returns the data of a pHYs chunk for dpi.
+func pngPhys(dpi float64) []byte {
+	// Pixels per metre.
+	ppm := uint32(math.Round(dpi / 0.0254))
+
+	data := make([]byte, 9)
+	binary.BigEndian.PutUint32(data[0:], ppm)
+	binary.BigEndian.PutUint32(data[4:], ppm)
+	data[8] = 1 // Unit is the metre.
+
+	return data
+}
+
+// jpegWithDPI inserts a JFIF APP0 segment after the SOI marker of an encoded
+// JPEG, which image/jpeg does not write.
+func jpegWithDPI(b []byte, dpi float64) []byte {
+	if len(b) < 2 {
+		return b
+	}
+
+	density := math.Round(dpi)
+	if density > math.MaxUint16 {
+		density = math.MaxUint16
+	}
+
+	segment := []byte{
+		0xff, 0xe0, // APP0 marker.
+		0x00, 0x10, // Segment length.
+		'J', 'F', 'I', 'F', 0x00,
+		0x01, 0x01, // Version 1.01.
+		0x01, // Density is in dots per inch.
+		0x00, 0x00,
+		0x00, 0x00,
+		0x00, 0x00, // No thumbnail.
+	}
+	binary.BigEndian.PutUint16(segment[12:], uint16(density))
+	binary.BigEndian.PutUint16(segment[14:], uint16(density))
+
+	var out bytes.Buffer
+
+	out.Write(b[:2])
+	out.Write(segment)
+	out.Write(b[2:])
+
+	return out.Bytes()
+}
@@ -0,0 +1,113 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPhysicalSize(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.DPI = 300
+
+	// 25 millimetres at 300 DPI are 295 dots, whatever the scale mode.
+	q.Width = 25 * Millimeter
+
+	for _, c := range []struct {
+		mode ScaleMode
+		size int
+	}{
+		{ScaleStretch, 100},
+		{ScaleModule, 4},
+		{ScaleStretch, -4},
+	} {
+		q.Scale = c.mode
+
+		img, err := q.Image(c.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if w := img.Bounds().Dx(); w != 295 {
+			t.Errorf("Width with mode %d and size %d: %d pixels wide, want 295", c.mode, c.size, w)
+		}
+	}
+
+	// The table is as wide as it can be in whole pixels per module.
+	q.Scale = ScaleModule
+
+	html, err := q.HTML(4, HTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := q.symbol.size
+	if want := fmt.Sprintf("width:%dpx", 295/n*n); !strings.Contains(string(html), want) {
+		t.Errorf("HTML with Width: no %q", want)
+	}
+
+	// A module of 1/30 inch is 10 dots.
+	q.Width = 0
+	q.ModuleSize = Inch / 30
+	q.Scale = ScaleStretch
+
+	img, err := q.Image(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := img.Bounds().Dx(); w != 10*n {
+		t.Errorf("ModuleSize: %d pixels wide, want %d", w, 10*n)
+	}
+}
+
+func TestDPIMetadata(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.DPI = 300
+
+	b, err := q.PNG(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The pHYs chunk follows IHDR: 300 DPI is 11811 pixels per metre.
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+
+	chunk := b[ihdrEnd:]
+	if string(chunk[4:8]) != "pHYs" || binary.BigEndian.Uint32(chunk) != 9 {
+		t.Fatalf("no pHYs chunk after IHDR: % x", chunk[:8])
+	}
+
+	if x, y, unit := binary.BigEndian.Uint32(chunk[8:]), binary.BigEndian.Uint32(chunk[12:]), chunk[16]; x != 11811 || y != 11811 || unit != 1 {
+		t.Errorf("pHYs: %d x %d per unit %d, want 11811 x 11811 per metre", x, y, unit)
+	}
+
+	b, err = q.JPEG(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The JFIF APP0 segment follows SOI, with the density in dots per inch.
+	if !bytes.Equal(b[:4], []byte{0xff, 0xd8, 0xff, 0xe0}) || string(b[6:11]) != "JFIF\x00" {
+		t.Fatalf("no JFIF segment after SOI: % x", b[:11])
+	}
+
+	if unit, x, y := b[13], binary.BigEndian.Uint16(b[14:]), binary.BigEndian.Uint16(b[16:]); unit != 1 || x != 300 || y != 300 {
+		t.Errorf("JFIF: %d x %d per unit %d, want 300 x 300 per inch", x, y, unit)
+	}
+}
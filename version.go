@@ -2919,8 +2919,6 @@ func (v qrCodeVersion) numDataBits() int {
 }
 
 func chooseQRCodeVersion(level RecoveryLevel, encoder *dataEncoder, numDataBits int) *qrCodeVersion {
-	var chosenVersion qrCodeVersion
-
 	for _, v := range versions {
 		if v.level != level {
 			continue
@@ -2933,12 +2931,12 @@ func chooseQRCodeVersion(level RecoveryLevel, encoder *dataEncoder, numDataBits
 		numFreeBits := v.numDataBits() - numDataBits
 
 		if numFreeBits >= 0 {
-			chosenVersion = v
-			break
+			chosenVersion := v
+			return &chosenVersion
 		}
 	}
 
-	return &chosenVersion
+	return nil
 }
 
 func (v qrCodeVersion) numTerminatorBitsRequired(numDataBits int) int {
@@ -2966,6 +2964,63 @@ func (v qrCodeVersion) numBlocks() int {
 	return numBlocks
 }
 
+func (v qrCodeVersion) numCodewords() int {
+	numCodewords := 0
+
+	for _, b := range v.block {
+		numCodewords += b.numBlocks * b.numCodewords
+	}
+
+	return numCodewords
+}
+
+// numMisdecodeProtection returns the number of error correction codewords per
+// block the smallest versions reserve against misdecoding, which can't be used
+// to correct errors (ISO/IEC 18004 table 9).
+func (v qrCodeVersion) numMisdecodeProtection() int {
+	switch {
+	case v.version == 1 && v.level == Low:
+		return 3
+	case v.version == 1 && v.level == Medium, v.version == 2 && v.level == Low:
+		return 2
+	case v.version == 1, v.version == 3 && v.level == Low:
+		return 1
+	}
+
+	return 0
+}
+
+// codewordBlocks returns the block each codeword of the interleaved stream
+// belongs to, numbering blocks in the order of v.block.
+func (v qrCodeVersion) codewordBlocks() []int {
+	var dataLen, ecLen []int
+
+	for _, b := range v.block {
+		for j := 0; j < b.numBlocks; j++ {
+			dataLen = append(dataLen, b.numDataCodewords)
+			ecLen = append(ecLen, b.numCodewords-b.numDataCodewords)
+		}
+	}
+
+	result := make([]int, 0, v.numCodewords())
+
+	// Data codewords are interleaved first, then error correction codewords.
+	for _, lengths := range [][]int{dataLen, ecLen} {
+		for i, working := 0, true; working; i++ {
+			working = false
+
+			for j, n := range lengths {
+				if i < n {
+					result = append(result, j)
+					working = true
+				}
+			}
+		}
+	}
+
+	return result
+}
+
 func (v qrCodeVersion) numBitsToPadToCodeword(numDataBits int) int {
 	if numDataBits == v.numDataBits() {
 		return 0
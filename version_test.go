@@ -0,0 +1,31 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChooseQRCodeVersionTooLong(t *testing.T) {
+	encoder, err := newDataEncoder(dataEncoderType1To9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// More data than version 9 holds at any level.
+	if v := chooseQRCodeVersion(Low, encoder, 8*2000); v != nil {
+		t.Errorf("got version %d, want none", v.version)
+	}
+}
+
+func TestNewUsesLargerEncoders(t *testing.T) {
+	for _, length := range []int{500, 2000} {
+		q, err := New(strings.Repeat("a", length), Low)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", length, err)
+		}
+
+		if q.versionNumber < 10 {
+			t.Errorf("%d bytes: version %d, want 10 or more", length, q.versionNumber)
+		}
+	}
+}
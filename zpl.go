@@ -0,0 +1,163 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// ZPLMode selects how ZPL output draws the QR Code.
+type ZPLMode int
+
+const (
+	// ZPLGraphic sends the modules as a compressed ^GFA graphic field, drawn
+	// exactly as the other renderers draw them.
+	ZPLGraphic ZPLMode = iota
+	// ZPLNative uses the printer's own ^BQ barcode command. The printer
+	// encodes the content itself and ignores the drawing options.
+	ZPLNative
+)
+
+// ZPLOptions style ZPL output.
+type ZPLOptions struct {
+	Mode ZPLMode
+
+	// Position of the QR Code on the label, in dots.
+	Origin image.Point
+}
+
+// ZPL returns a label in the Zebra Programming Language printing the QR Code
+// with size dots per module. ZPLNative limits size to 1 to 10.
+func (q *QRCode) ZPL(size int, opts ZPLOptions) ([]byte, error) {
+	if size < 1 || opts.Mode == ZPLNative && size > 10 {
+		return nil, fmt.Errorf("ZPL magnification %d out of range", size)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("^XA\n")
+	fmt.Fprintf(&b, "^FO%d,%d", opts.Origin.X, opts.Origin.Y)
+
+	if opts.Mode == ZPLNative {
+		// Field data starts with the error correction level and automatic
+		// input mode. ^FH escapes characters ZPL would interpret.
+		fmt.Fprintf(&b, "^BQN,2,%d^FH^FD%cA,%s^FS\n", size, "LMQH"[q.level], zplEscape(q.content))
+	} else {
+		if err := q.encode(); err != nil {
+			return nil, err
+		}
+
+		b.WriteString(q.zplGraphic(q.layout(size, ScaleModule)))
+		b.WriteString("^FS\n")
+	}
+
+	b.WriteString("^XZ\n")
+
+	return []byte(b.String()), nil
+}
+
+// zplEscape escapes the ZPL control characters, the ^FH escape character
+// and non-printable bytes of s as hexadecimal.
+func zplEscape(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c < 0x20 || c > 0x7e || c == '^' || c == '~' || c == '_' {
+			fmt.Fprintf(&b, "_%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// zplGraphic returns a ^GFA command drawing the QR Code laid out by l, its
+// rows compressed with ZPL's ASCII hexadecimal compression.
+func (q *QRCode) zplGraphic(l rasterLayout) string {
+	img := q.bilevelLayout(l)
+
+	n := img.Bounds().Dx()
+	bytesPerRow := (n + 7) / 8
+
+	var data strings.Builder
+
+	row := make([]byte, bytesPerRow)
+	previous := ""
+
+	for y := 0; y < n; y++ {
+		q.packBits(row, img, y, false, false)
+
+		line := fmt.Sprintf("%X", row)
+
+		if line == previous {
+			data.WriteByte(':')
+			continue
+		}
+
+		previous = line
+
+		data.WriteString(zplCompress(line))
+	}
+
+	return fmt.Sprintf("^GFA,%d,%d,%d,%s", bytesPerRow*n, bytesPerRow*n, bytesPerRow, data.String())
+}
+
+// zplCompress compresses a row of hexadecimal digits: a row ending in zeros
+// or ones ends with ',' or '!', and repeated digits are prefixed with their
+// count.
+func zplCompress(line string) string {
+	var b strings.Builder
+
+	end := ""
+
+	if t := strings.TrimRight(line, "0"); len(t) < len(line) {
+		line, end = t, ","
+	} else if t := strings.TrimRight(line, "F"); len(t) < len(line) {
+		line, end = t, "!"
+	}
+
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && line[j] == line[i] {
+			j++
+		}
+
+		b.WriteString(zplCount(j - i))
+		b.WriteByte(line[i])
+
+		i = j
+	}
+
+	b.WriteString(end)
+
+	return b.String()
+}
+
+// zplCount returns the repeat count prefix for n digits: 'g' to 'z' count
+// 20 to 400 in steps of 20, and 'G' to 'Y' add 1 to 19.
+func zplCount(n int) string {
+	if n == 1 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	for n >= 20 {
+		m := n / 20
+		if m > 20 {
+			m = 20
+		}
+
+		b.WriteByte(byte('g' + m - 1))
+		n -= m * 20
+	}
+
+	if n > 0 {
+		b.WriteByte(byte('G' + n - 1))
+	}
+
+	return b.String()
+}
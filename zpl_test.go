@@ -0,0 +1,123 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestZPLNative(t *testing.T) {
+	// Characters ZPL would interpret are escaped.
+	q, err := New("https://example.org/?a=^b~c_d", High)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := q.ZPL(4, ZPLOptions{Mode: ZPLNative, Origin: image.Pt(20, 30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "native.zpl", b)
+
+	if _, err := q.ZPL(11, ZPLOptions{Mode: ZPLNative}); err == nil {
+		t.Error("magnification 11 accepted")
+	}
+}
+
+func TestZPLGraphic(t *testing.T) {
+	q, err := New("https://example.org", Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := q.ZPL(3, ZPLOptions{Origin: image.Pt(10, 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden(t, "graphic.zpl", b)
+
+	// The compressed rows expand back to the bilevel image.
+	m := regexp.MustCompile(`\^GFA,(\d+),(\d+),(\d+),([^^]*)\^FS`).FindStringSubmatch(string(b))
+	if m == nil {
+		t.Fatalf("no ^GFA field in %q", b)
+	}
+
+	bytesPerRow, err := strconv.Atoi(m[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := q.bilevelLayout(q.layout(3, ScaleModule))
+	row := make([]byte, bytesPerRow)
+	previous := ""
+
+	rows := zplExpand(t, m[4], bytesPerRow*2)
+	if len(rows) != img.Rect.Dy() {
+		t.Fatalf("%d rows, want %d", len(rows), img.Rect.Dy())
+	}
+
+	for y, line := range rows {
+		if line == ":" {
+			line = previous
+		}
+
+		q.packBits(row, img, y, false, false)
+
+		if want := fmt.Sprintf("%X", row); line != want {
+			t.Fatalf("row %d = %s, want %s", y, line, want)
+		}
+
+		previous = line
+	}
+}
+
+// zplExpand splits ZPL compressed graphic data into rows of n hexadecimal
+// digits, keeping ':' for rows repeating the previous one.
+func zplExpand(t *testing.T, data string, n int) []string {
+	var rows []string
+
+	var line strings.Builder
+
+	count := 0
+
+	for _, c := range data {
+		switch {
+		case c == ':':
+			rows = append(rows, ":")
+		case c >= 'G' && c <= 'Y':
+			count += int(c-'G') + 1
+		case c >= 'g' && c <= 'z':
+			count += (int(c-'g') + 1) * 20
+		case c == ',' || c == '!':
+			fill := "0"
+			if c == '!' {
+				fill = "F"
+			}
+
+			line.WriteString(strings.Repeat(fill, n-line.Len()))
+		default:
+			if count == 0 {
+				count = 1
+			}
+
+			line.WriteString(strings.Repeat(string(c), count))
+			count = 0
+		}
+
+		if line.Len() > n {
+			t.Fatalf("row longer than %d digits: %s", n, line.String())
+		}
+
+		if line.Len() == n {
+			rows = append(rows, line.String())
+			line.Reset()
+		}
+	}
+
+	return rows
+}